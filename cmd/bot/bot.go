@@ -4,17 +4,15 @@ package main
 import (
 	_ "embed"
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 
 	"github.com/topi314/tint"
 	"github.com/zokiio/mukabi/internal/config"
 	"github.com/zokiio/mukabi/internal/log"
 	"github.com/zokiio/mukabi/service/bot"
-	"github.com/zokiio/mukabi/service/bot/commands"
-	"github.com/zokiio/mukabi/service/bot/events"
 )
 
 // Version information set during build
@@ -23,46 +21,109 @@ var (
 	Commit  = "local"
 )
 
+// command is a CLI subcommand
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(cfg *bot.Config, fs *flag.FlagSet) error
+	flags       func(fs *flag.FlagSet) // Registers subcommand specific flags, may be nil
+}
+
+// subcommands lists all available CLI subcommands
+var subcommands []command
+
+func init() {
+	subcommands = []command{
+		{name: "run", description: "Run the bot (default)", run: runBot, flags: runFlags},
+		{name: "check-config", description: "Load and validate the configuration and print it with secrets masked", run: checkConfig},
+		{name: "migrate", description: "Apply the database schema", run: migrate},
+		{name: "sync-commands", description: "Sync application commands with Discord", run: syncCommands, flags: syncFlags},
+		{name: "export", usage: "[file]", description: "Export database data to a file or stdout", run: exportData, flags: exportFlags},
+		{name: "import", usage: "[file]", description: "Import database data from a JSON or NDJSON archive", run: importData},
+		{name: "lookup", usage: "<region> <realm> <name>", description: "Fetch a character profile from Raider.IO", run: lookup},
+	}
+}
+
 func main() {
-	// Parse command line flags
-	cfgPath := flag.String("config", "config.toml", "path to config file")
-	flag.Parse()
+	args := os.Args[1:]
+
+	// Without a subcommand, flags are passed to run for backwards compatibility
+	name := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" {
+		usage()
+		return
+	}
+
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	cfgPath := fs.String("config", "config.toml", "path to config file, optional if configured through MUKABI_* environment variables")
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: mukabi %s [flags] %s\n\n%s\n\nFlags:\n", cmd.name, cmd.usage, cmd.description)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
 
-	// Load configuration
-	cfg, err := config.Load(*cfgPath)
+	cfg, err := loadConfig(*cfgPath)
 	if err != nil {
 		slog.Error("Failed to load config", tint.Err(err))
 		os.Exit(1)
 	}
 
-	// Setup logging
-	slog.Info("Config loaded", slog.String("config", cfg.String()))
-	log.Setup(cfg.Log)
+	err = cmd.run(cfg, fs)
+	if err != nil {
+		slog.Error("Command failed", slog.String("command", cmd.name), tint.Err(err))
+	}
 
-	// Initialize bot
-	b, err := bot.New(*cfg, Version, Commit)
+	// Flush logs pending for Discord and close the log file
+	log.Close()
 	if err != nil {
-		slog.Error("Failed to create bot", tint.Err(err))
 		os.Exit(1)
 	}
-	defer b.Close()
-
-	// Register event handlers
-	b.Discord.AddEventListeners(
-		commands.New(b),
-		events.New(b),
-	)
-
-	// Start bot
-	if err = b.Start(commands.Commands()); err != nil {
-		// If the bot fails to start, log the error and exit
-		slog.Error("Failed to start bot", tint.Err(err))
-		os.Exit(1)
+}
+
+// loadConfig loads the configuration and sets up logging, shared by all subcommands
+func loadConfig(path string) (*bot.Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
 	}
 
-	// Wait for shutdown signal
-	slog.Info("Bot is running. Press CTRL-C to exit.")
-	sc := make(chan os.Signal, 1)
-	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM)
-	<-sc
+	if err := log.Setup(cfg.Log, cfg.LogOptions()...); err != nil {
+		return nil, fmt.Errorf("failed to set up logging: %w", err)
+	}
+	slog.Debug("Config loaded", slog.String("config", cfg.String()))
+	return cfg, nil
+}
+
+// findCommand looks up a subcommand by name
+func findCommand(name string) (command, bool) {
+	for _, cmd := range subcommands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// usage prints the list of available subcommands
+func usage() {
+	fmt.Fprintf(os.Stderr, "Mukabi %s (%s)\n\nUsage: mukabi <command> [flags] [arguments]\n\nCommands:\n", Version, Commit)
+	for _, cmd := range subcommands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun 'mukabi <command> -h' for command flags.")
 }
@@ -0,0 +1,163 @@
+// Package main is the entry point for the Discord bot
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/zokiio/mukabi/external"
+	"github.com/zokiio/mukabi/external/raiderio"
+	"github.com/zokiio/mukabi/service/bot"
+	"github.com/zokiio/mukabi/service/bot/commands"
+	"github.com/zokiio/mukabi/service/bot/db"
+)
+
+// checkConfig prints the loaded configuration with secrets masked
+func checkConfig(cfg *bot.Config, _ *flag.FlagSet) error {
+	fmt.Println("Configuration is valid:")
+	fmt.Println(cfg.String())
+	return nil
+}
+
+// migrate connects to the database and applies the schema
+func migrate(cfg *bot.Config, _ *flag.FlagSet) error {
+	database, err := bot.OpenDatabase(*cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	slog.Info("Database schema applied")
+	return nil
+}
+
+// syncFlags registers the flags of the sync-commands subcommand
+func syncFlags(fs *flag.FlagSet) {
+	fs.Bool("dry-run", false, "print the changes without applying them")
+}
+
+// syncCommands syncs application commands with Discord without connecting to the gateway
+func syncCommands(cfg *bot.Config, fs *flag.FlagSet) error {
+	if flagBool(fs, "dry-run") {
+		return planCommands(cfg)
+	}
+
+	syncer, err := bot.NewCommandSyncer(*cfg)
+	if err != nil {
+		return err
+	}
+	defer syncer.Close()
+
+	return syncer.Sync(commands.Commands(cfg.Bot.DevMode))
+}
+
+// planCommands prints the changes a command sync would make.
+// Only the REST API is used, so planning never touches the database.
+func planCommands(cfg *bot.Config) error {
+	syncer, err := bot.NewCommandSyncer(*cfg)
+	if err != nil {
+		return err
+	}
+	defer syncer.Close()
+
+	changes, err := syncer.Plan(commands.Commands(cfg.Bot.DevMode))
+	if err != nil {
+		return fmt.Errorf("failed to plan command sync: %w", err)
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("Commands are up to date")
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	return nil
+}
+
+// exportFlags registers the flags of the export subcommand
+func exportFlags(fs *flag.FlagSet) {
+	fs.String("format", string(db.FormatNDJSON), "archive format: json or ndjson")
+}
+
+// exportData writes all database data to the file given as argument, or stdout
+func exportData(cfg *bot.Config, fs *flag.FlagSet) error {
+	format, err := db.ParseFormat(fs.Lookup("format").Value.String())
+	if err != nil {
+		return err
+	}
+
+	database, err := bot.OpenDatabase(*cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	var w io.Writer = os.Stdout
+	if path := fs.Arg(0); path != "" && path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	summary, err := database.Export(context.Background(), w, format)
+	if err != nil {
+		return err
+	}
+	slog.Info("Export completed", slog.String("rows", summary.String()))
+	return nil
+}
+
+// importData reads database data from the file given as argument, or stdin
+func importData(cfg *bot.Config, fs *flag.FlagSet) error {
+	database, err := bot.OpenDatabase(*cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	var r io.Reader = os.Stdin
+	if path := fs.Arg(0); path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	summary, err := database.Import(context.Background(), r)
+	if err != nil {
+		return err
+	}
+	slog.Info("Import completed", slog.String("rows", summary.String()))
+	return nil
+}
+
+// lookup fetches a character profile from Raider.IO and prints it as JSON
+func lookup(cfg *bot.Config, fs *flag.FlagSet) error {
+	if fs.NArg() != 3 {
+		fs.Usage()
+		return errors.New("expected <region> <realm> <name>")
+	}
+
+	services := external.New(external.WithRaiderIOKey(cfg.External.RaiderIOKey))
+	profile, err := services.RaiderIO().FetchCharacterProfile(fs.Arg(0), fs.Arg(1), fs.Arg(2),
+		raiderio.WithFields(raiderio.FieldMythicPlusScoresBySeason),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to fetch character profile: %w", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(profile)
+}
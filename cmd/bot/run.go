@@ -0,0 +1,78 @@
+// Package main is the entry point for the Discord bot
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/topi314/tint"
+	"github.com/zokiio/mukabi/internal/config"
+	"github.com/zokiio/mukabi/service/bot"
+	"github.com/zokiio/mukabi/service/bot/commands"
+	"github.com/zokiio/mukabi/service/bot/events"
+)
+
+// runFlags registers the flags of the run subcommand
+func runFlags(fs *flag.FlagSet) {
+	fs.Bool("sync-dry-run", false, "print the slash command sync diff and exit")
+}
+
+// runBot starts the bot and blocks until a shutdown signal is received
+func runBot(cfg *bot.Config, fs *flag.FlagSet) error {
+	if flagBool(fs, "sync-dry-run") {
+		return planCommands(cfg)
+	}
+
+	// Initialize bot
+	b, err := bot.New(*cfg, Version, Commit)
+	if err != nil {
+		return fmt.Errorf("failed to create bot: %w", err)
+	}
+	defer b.Close()
+
+	// Register event handlers
+	b.Discord.AddEventListeners(
+		commands.New(b),
+		events.New(b),
+	)
+
+	// Start bot
+	if err = b.Start(commands.Commands(cfg.Bot.DevMode)); err != nil {
+		return fmt.Errorf("failed to start bot: %w", err)
+	}
+
+	// Wait for shutdown signal, reloading the configuration on SIGHUP
+	slog.Info("Bot is running. Press CTRL-C to exit.")
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := range sc {
+		if sig != syscall.SIGHUP {
+			return nil
+		}
+		reloadConfig(b, fs.Lookup("config").Value.String())
+	}
+	return nil
+}
+
+// reloadConfig loads and validates the configuration again and applies it to the running bot.
+// An invalid configuration is logged and the current one is kept.
+func reloadConfig(b *bot.Bot, path string) {
+	slog.Info("Reloading configuration", slog.String("path", path))
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		slog.Error("Failed to reload config, keeping current configuration", tint.Err(err))
+		return
+	}
+	b.Reload(*cfg)
+}
+
+// flagBool returns the value of a boolean flag registered on the flag set
+func flagBool(fs *flag.FlagSet, name string) bool {
+	f := fs.Lookup(name)
+	return f != nil && f.Value.String() == "true"
+}
@@ -5,22 +5,43 @@ import "github.com/zokiio/mukabi/external/raiderio"
 
 // ExternalAPI defines the interface for accessing external services
 type ExternalAPI interface {
-	RaiderIO() *raiderio.Client
+	RaiderIO() raiderio.API
 }
 
 // Services implements ExternalAPI interface and holds external service clients
 type Services struct {
-	raiderIO *raiderio.Client
+	raiderIO raiderio.API
 }
 
-// NewServices creates a new Services instance with configured external clients
-func NewServices(raiderIOKey string) *Services {
-	return &Services{
-		raiderIO: raiderio.New(raiderIOKey),
+// Option configures the external services
+type Option func(*Services)
+
+// WithRaiderIO sets the Raider.IO implementation, e.g. a configured client or a fake
+func WithRaiderIO(api raiderio.API) Option {
+	return func(s *Services) {
+		s.raiderIO = api
+	}
+}
+
+// WithRaiderIOKey uses a Raider.IO API client authenticated with the given key
+func WithRaiderIOKey(apiKey string) Option {
+	return WithRaiderIO(raiderio.New(apiKey))
+}
+
+// New creates a new Services instance from the given options.
+// Services that are not configured use unauthenticated API clients.
+func New(opts ...Option) *Services {
+	s := &Services{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.raiderIO == nil {
+		s.raiderIO = raiderio.New("")
 	}
+	return s
 }
 
-// RaiderIO returns the RaiderIO client instance
-func (s *Services) RaiderIO() *raiderio.Client {
+// RaiderIO returns the Raider.IO implementation
+func (s *Services) RaiderIO() raiderio.API {
 	return s.raiderIO
 }
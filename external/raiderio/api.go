@@ -0,0 +1,64 @@
+// Package raiderio provides integration with the Raider.IO API for World of Warcraft character and realm data.
+package raiderio
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// API is the Raider.IO functionality used by the bot.
+// It is implemented by Client and by the in-memory fake in the raiderio/fake package.
+type API interface {
+	// FetchConnectedRealms returns the realms of a region ranked by how well they match the query
+	FetchConnectedRealms(region string, query string) ([]FilteredRealm, error)
+
+	// FetchCharacterProfile returns the profile of a character with the requested optional fields
+	FetchCharacterProfile(region, realm, character string, opts ...FetchCharacterOption) (*CharacterProfile, error)
+}
+
+var _ API = (*Client)(nil)
+
+// StatusError is returned when the API responds with a non-OK status code
+type StatusError struct {
+	StatusCode int
+	URL        string // Request URL with the access key redacted
+}
+
+func (e *StatusError) Error() string {
+	if e.URL == "" {
+		return fmt.Sprintf("received status code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("received status code %d for %s", e.StatusCode, e.URL)
+}
+
+// NotFound reports whether the API did not find the requested resource.
+// Raider.IO responds with 400 Bad Request for unknown characters.
+func (e *StatusError) NotFound() bool {
+	return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusNotFound
+}
+
+// ApplyOptions returns the fields requested by the given options
+func ApplyOptions(opts ...FetchCharacterOption) []string {
+	cfg := &fetchCharacterConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg.fields
+}
+
+// Filtered maps the connected realms of a region to the condensed realm information used for search
+func (r ConnectedRealms) Filtered(region string) []FilteredRealm {
+	var filteredRealms []FilteredRealm
+	for _, realm := range r.RealmListing.Realms {
+		for _, connectedRealm := range realm.ConnectedRealms {
+			altName, _ := connectedRealm.AltName.(string)
+			filteredRealms = append(filteredRealms, FilteredRealm{
+				Region:  region,
+				Realm:   connectedRealm.Name,
+				Slug:    connectedRealm.Slug,
+				AltName: altName,
+			})
+		}
+	}
+	return filteredRealms
+}
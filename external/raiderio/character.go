@@ -1,7 +1,13 @@
 // Package raiderio provides integration with the Raider.IO API
 package raiderio
 
-import "time"
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zokiio/mukabi/internal/wow"
+)
 
 // CharacterProfile represents detailed information about a World of Warcraft character
 type CharacterProfile struct {
@@ -22,6 +28,17 @@ type CharacterProfile struct {
 	MythicPlusScoresBySeason []MythicPlusScoresBySeason `json:"mythic_plus_scores_by_season"`
 }
 
+// RealmSlug returns the slug of the character's realm, taken from the profile URL
+// (https://raider.io/characters/{region}/{realm}/{name}) or derived from the realm name
+func (p CharacterProfile) RealmSlug() string {
+	if u, err := url.Parse(p.ProfileURL); err == nil {
+		if parts := strings.Split(strings.Trim(u.Path, "/"), "/"); len(parts) == 4 && parts[0] == "characters" && parts[2] != "" {
+			return parts[2]
+		}
+	}
+	return wow.RealmSlug(p.Realm)
+}
+
 // MythicPlusScoresBySeason represents Mythic+ scores for a specific season
 type MythicPlusScoresBySeason struct {
 	Season   string             `json:"season"`   // Season identifier
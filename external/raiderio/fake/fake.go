@@ -0,0 +1,186 @@
+// Package fake provides an in-memory implementation of the Raider.IO API for tests and local development
+package fake
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zokiio/mukabi/external/raiderio"
+	"github.com/zokiio/mukabi/internal/wow"
+)
+
+// Fixtures contains the default characters, connected realms, guilds and affixes in the format
+// returned by the Raider.IO API. Characters and guilds are stored as
+// characters/<region>-<realm>-<name>.json and guilds/<region>-<realm>-<name>.json, realms
+// and affixes as connected-realms/<region>.json and affixes/<region>.json.
+// The fake client only uses characters and realms.
+//
+//go:embed fixtures
+var Fixtures embed.FS
+
+// Call records a request made to the fake
+type Call struct {
+	Method string   // FetchConnectedRealms or FetchCharacterProfile
+	Region string   // Requested region
+	Realm  string   // Requested realm, empty for realm searches
+	Name   string   // Requested character name, or the search query for realm searches
+	Fields []string // Requested profile fields
+}
+
+// Client is an in-memory Raider.IO API seeded with fixtures
+type Client struct {
+	mu         sync.RWMutex
+	characters map[string]raiderio.CharacterProfile
+	realms     map[string][]raiderio.FilteredRealm
+	err        error
+	calls      []Call
+}
+
+var _ raiderio.API = (*Client)(nil)
+
+// New creates an empty fake
+func New() *Client {
+	return &Client{
+		characters: map[string]raiderio.CharacterProfile{},
+		realms:     map[string][]raiderio.FilteredRealm{},
+	}
+}
+
+// NewWithFixtures creates a fake seeded with the default fixtures
+func NewWithFixtures() (*Client, error) {
+	sub, err := fs.Sub(Fixtures, "fixtures")
+	if err != nil {
+		return nil, err
+	}
+	return NewFromFS(sub)
+}
+
+// NewFromFS creates a fake seeded with the fixtures in the given file system
+func NewFromFS(fsys fs.FS) (*Client, error) {
+	c := New()
+	if err := c.Load(fsys); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load adds the characters and connected realms of a fixture file system
+func (c *Client) Load(fsys fs.FS) error {
+	characters, err := fs.Glob(fsys, "characters/*.json")
+	if err != nil {
+		return err
+	}
+	for _, name := range characters {
+		var profile raiderio.CharacterProfile
+		if err := readJSON(fsys, name, &profile); err != nil {
+			return err
+		}
+		c.AddCharacter(profile)
+	}
+
+	realms, err := fs.Glob(fsys, "connected-realms/*.json")
+	if err != nil {
+		return err
+	}
+	for _, name := range realms {
+		var connectedRealms raiderio.ConnectedRealms
+		if err := readJSON(fsys, name, &connectedRealms); err != nil {
+			return err
+		}
+		region := strings.TrimSuffix(path.Base(name), ".json")
+		c.AddRealms(region, connectedRealms.Filtered(region)...)
+	}
+	return nil
+}
+
+// readJSON decodes a JSON fixture file
+func readJSON(fsys fs.FS, name string, v any) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fmt.Errorf("failed to read fixture %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode fixture %s: %w", name, err)
+	}
+	return nil
+}
+
+// AddCharacter adds or replaces a character, identified by its region, realm and name
+func (c *Client) AddCharacter(profile raiderio.CharacterProfile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.characters[characterKey(profile.Region, profile.Realm, profile.Name)] = profile
+}
+
+// AddRealms adds realms to a region
+func (c *Client) AddRealms(region string, realms ...raiderio.FilteredRealm) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, realm := range realms {
+		realm.Region = region
+		c.realms[region] = append(c.realms[region], realm)
+	}
+}
+
+// SetError makes all following calls fail with err, nil restores normal behaviour
+func (c *Client) SetError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// Calls returns the calls made so far
+func (c *Client) Calls() []Call {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Clone(c.calls)
+}
+
+// FetchConnectedRealms searches the realms of a region like the Raider.IO client
+func (c *Client) FetchConnectedRealms(region string, query string) ([]raiderio.FilteredRealm, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = append(c.calls, Call{Method: "FetchConnectedRealms", Region: region, Name: query})
+	if c.err != nil {
+		return nil, c.err
+	}
+	if _, err := wow.ParseRegion(region); err != nil {
+		return nil, fmt.Errorf("error fetching connected realms: %w", err)
+	}
+	return raiderio.SearchRealms(c.realms[region], query), nil
+}
+
+// FetchCharacterProfile returns a stored character. Unknown characters fail with a
+// raiderio.StatusError like the API, and seasonal scores are only included when requested.
+func (c *Client) FetchCharacterProfile(region, realm, character string, opts ...raiderio.FetchCharacterOption) (*raiderio.CharacterProfile, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fields := raiderio.ApplyOptions(opts...)
+	c.calls = append(c.calls, Call{Method: "FetchCharacterProfile", Region: region, Realm: realm, Name: character, Fields: fields})
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	profile, ok := c.characters[characterKey(region, realm, character)]
+	if !ok {
+		return nil, &raiderio.StatusError{StatusCode: http.StatusBadRequest}
+	}
+	if !slices.Contains(fields, raiderio.FieldMythicPlusScoresBySeason) {
+		profile.MythicPlusScoresBySeason = nil
+	}
+	return &profile, nil
+}
+
+// characterKey identifies a character case-insensitively, accepting realm names or slugs
+func characterKey(region, realm, name string) string {
+	return strings.ToLower(region) + "/" + wow.RealmSlug(realm) + "/" + strings.ToLower(name)
+}
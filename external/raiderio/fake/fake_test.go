@@ -0,0 +1,111 @@
+package fake_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zokiio/mukabi/external/raiderio"
+	"github.com/zokiio/mukabi/external/raiderio/fake"
+)
+
+func newClient(t *testing.T) *fake.Client {
+	t.Helper()
+	c, err := fake.NewWithFixtures()
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %s", err)
+	}
+	return c
+}
+
+func TestFetchCharacterProfile(t *testing.T) {
+	c := newClient(t)
+
+	// Names are case-insensitive and realms may be names or slugs
+	profile, err := c.FetchCharacterProfile("EU", "Twisting Nether", "zoki", raiderio.WithFields(raiderio.FieldMythicPlusScoresBySeason))
+	if err != nil {
+		t.Fatalf("FetchCharacterProfile() error = %s", err)
+	}
+	if profile.Name != "Zoki" || len(profile.MythicPlusScoresBySeason) == 0 {
+		t.Errorf("FetchCharacterProfile() = %s with %d seasons, want Zoki with scores", profile.Name, len(profile.MythicPlusScoresBySeason))
+	}
+	if slug := profile.RealmSlug(); slug != "twisting-nether" {
+		t.Errorf("RealmSlug() = %q, want twisting-nether", slug)
+	}
+
+	// Seasonal scores are only included when requested
+	profile, err = c.FetchCharacterProfile("eu", "twisting-nether", "Zoki", raiderio.WithFields(raiderio.FieldGear))
+	if err != nil {
+		t.Fatalf("FetchCharacterProfile() error = %s", err)
+	}
+	if profile.MythicPlusScoresBySeason != nil {
+		t.Error("FetchCharacterProfile() without the scores field returned scores")
+	}
+
+	_, err = c.FetchCharacterProfile("eu", "ravencrest", "Zoki")
+	var statusErr *raiderio.StatusError
+	if !errors.As(err, &statusErr) || !statusErr.NotFound() {
+		t.Errorf("FetchCharacterProfile() on another realm = %v, want not found", err)
+	}
+
+	calls := c.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("Calls() = %d calls, want 3", len(calls))
+	}
+	want := fake.Call{Method: "FetchCharacterProfile", Region: "EU", Realm: "Twisting Nether", Name: "zoki"}
+	if got := calls[0]; got.Method != want.Method || got.Region != want.Region || got.Realm != want.Realm || got.Name != want.Name {
+		t.Errorf("Calls()[0] = %+v, want %+v", got, want)
+	}
+	if fields := calls[0].Fields; len(fields) != 1 || fields[0] != raiderio.FieldMythicPlusScoresBySeason {
+		t.Errorf("Calls()[0].Fields = %v, want [%s]", fields, raiderio.FieldMythicPlusScoresBySeason)
+	}
+}
+
+func TestFetchCharacterProfileDoesNotShareScores(t *testing.T) {
+	c := newClient(t)
+
+	// Filtering a response must not remove the scores from the stored character
+	if _, err := c.FetchCharacterProfile("eu", "twisting-nether", "Zoki"); err != nil {
+		t.Fatalf("FetchCharacterProfile() error = %s", err)
+	}
+	profile, err := c.FetchCharacterProfile("eu", "twisting-nether", "Zoki", raiderio.WithFields(raiderio.FieldMythicPlusScoresBySeason))
+	if err != nil {
+		t.Fatalf("FetchCharacterProfile() error = %s", err)
+	}
+	if len(profile.MythicPlusScoresBySeason) == 0 {
+		t.Error("scores missing after an earlier request without them")
+	}
+}
+
+func TestFetchConnectedRealms(t *testing.T) {
+	c := newClient(t)
+
+	realms, err := c.FetchConnectedRealms("eu", "twis")
+	if err != nil {
+		t.Fatalf("FetchConnectedRealms() error = %s", err)
+	}
+	if len(realms) == 0 || realms[0].Slug != "twisting-nether" || realms[0].Region != "eu" {
+		t.Errorf("FetchConnectedRealms(twis) = %+v, want twisting-nether first", realms)
+	}
+
+	if _, err := c.FetchConnectedRealms("xx", ""); err == nil {
+		t.Error("FetchConnectedRealms() with unknown region succeeded, want error")
+	}
+}
+
+func TestSetError(t *testing.T) {
+	c := newClient(t)
+	injected := errors.New("unavailable")
+
+	c.SetError(injected)
+	if _, err := c.FetchCharacterProfile("eu", "twisting-nether", "Zoki"); !errors.Is(err, injected) {
+		t.Errorf("FetchCharacterProfile() = %v, want %v", err, injected)
+	}
+	if _, err := c.FetchConnectedRealms("eu", ""); !errors.Is(err, injected) {
+		t.Errorf("FetchConnectedRealms() = %v, want %v", err, injected)
+	}
+
+	c.SetError(nil)
+	if _, err := c.FetchCharacterProfile("eu", "twisting-nether", "Zoki"); err != nil {
+		t.Errorf("FetchCharacterProfile() after clearing the error = %s", err)
+	}
+}
@@ -0,0 +1,379 @@
+// Package fakeserver provides a local HTTP server imitating the Raider.IO API, serving fixtures
+// with optional latency and error injection while recording requests for assertions.
+package fakeserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zokiio/mukabi/external/raiderio"
+	"github.com/zokiio/mukabi/external/raiderio/fake"
+	"github.com/zokiio/mukabi/internal/wow"
+)
+
+// Request is a request received by the server
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Time   time.Time
+	Status int // Status code of the response
+}
+
+// fault is an injected error response
+type fault struct {
+	status     int
+	remaining  int // Number of requests left to fail, negative fails until cleared
+	retryAfter time.Duration
+}
+
+// Server is a fake Raider.IO API backed by an httptest.Server.
+// Use APIURL with raiderio.WithAPIURL to point a client at it.
+type Server struct {
+	*httptest.Server
+
+	characters map[string]json.RawMessage // By region, realm slug and lowercase name
+	guilds     map[string]json.RawMessage // By region, realm slug and lowercase name
+	realms     map[string]json.RawMessage // By region
+	affixes    map[string]json.RawMessage // By region
+
+	mu       sync.Mutex
+	latency  time.Duration
+	fault    *fault
+	requests []Request
+}
+
+// New starts a server serving the fixtures in the given file system, laid out like fake.Fixtures.
+// Call Close when done.
+func New(fsys fs.FS) (*Server, error) {
+	s := &Server{
+		characters: map[string]json.RawMessage{},
+		guilds:     map[string]json.RawMessage{},
+		realms:     map[string]json.RawMessage{},
+		affixes:    map[string]json.RawMessage{},
+	}
+	if err := s.load(fsys); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/characters/profile", s.handleCharacter)
+	mux.HandleFunc("GET /api/v1/guilds/profile", s.handleGuild)
+	mux.HandleFunc("GET /api/v1/mythic-plus/affixes", s.handleAffixes)
+	mux.HandleFunc("GET /api/connected-realms", s.handleRealms)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s, nil
+}
+
+// NewWithFixtures starts a server serving the default fixtures of the fake package
+func NewWithFixtures() (*Server, error) {
+	sub, err := fs.Sub(fake.Fixtures, "fixtures")
+	if err != nil {
+		return nil, err
+	}
+	return New(sub)
+}
+
+// APIURL returns the base URL to configure clients with
+func (s *Server) APIURL() string {
+	return s.URL + "/api"
+}
+
+// Client returns a Raider.IO client using the server
+func (s *Server) Client(apiKey string) *raiderio.Client {
+	return raiderio.New(apiKey, raiderio.WithAPIURL(s.APIURL()), raiderio.WithHTTPClient(s.Server.Client()))
+}
+
+// SetLatency delays every response by d
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// FailNext responds to the next n requests with the given status code, n < 0 fails until ClearFaults
+func (s *Server) FailNext(status int, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fault = &fault{status: status, remaining: n}
+}
+
+// RateLimitNext responds to the next n requests with 429 Too Many Requests and a Retry-After header
+func (s *Server) RateLimitNext(n int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fault = &fault{status: http.StatusTooManyRequests, remaining: n, retryAfter: retryAfter}
+}
+
+// ClearFaults stops injecting errors
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fault = nil
+}
+
+// Requests returns all requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// RequestCount returns the number of requests received for a path, e.g. /api/connected-realms
+func (s *Server) RequestCount(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	for _, r := range s.requests {
+		if r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+// ResetRequests clears the recorded requests
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// middleware records requests and applies injected latency and faults
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		latency := s.latency
+		var injected *fault
+		if s.fault != nil {
+			f := *s.fault
+			injected = &f
+			if s.fault.remaining > 0 {
+				s.fault.remaining--
+				if s.fault.remaining == 0 {
+					s.fault = nil
+				}
+			}
+		}
+		s.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		if injected != nil {
+			if injected.retryAfter > 0 {
+				rec.Header().Set("Retry-After", strconv.Itoa(int(injected.retryAfter.Seconds())))
+			}
+			writeError(rec, injected.status, http.StatusText(injected.status))
+		} else {
+			next.ServeHTTP(rec, r)
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.Query(),
+			Time:   time.Now(),
+			Status: rec.status,
+		})
+		s.mu.Unlock()
+	})
+}
+
+func (s *Server) handleCharacter(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data, ok := s.characters[key(q.Get("region"), q.Get("realm"), q.Get("name"))]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Could not find requested character")
+		return
+	}
+
+	// Optional fields are only included when requested, like the API does
+	var fields []string
+	if f := q.Get("fields"); f != "" {
+		fields = strings.Split(f, ",")
+	}
+	writeJSON(w, withFields(data, fields))
+}
+
+func (s *Server) handleGuild(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	data, ok := s.guilds[key(q.Get("region"), q.Get("realm"), q.Get("name"))]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Could not find requested guild")
+		return
+	}
+	writeJSON(w, data)
+}
+
+func (s *Server) handleRealms(w http.ResponseWriter, r *http.Request) {
+	data, ok := s.realms[strings.ToLower(r.URL.Query().Get("region"))]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid region")
+		return
+	}
+	writeJSON(w, data)
+}
+
+func (s *Server) handleAffixes(w http.ResponseWriter, r *http.Request) {
+	data, ok := s.affixes[strings.ToLower(r.URL.Query().Get("region"))]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid region")
+		return
+	}
+	writeJSON(w, data)
+}
+
+// withFields removes optional character fields that were not requested.
+// Field names may carry a qualifier, e.g. mythic_plus_scores_by_season:current.
+func withFields(data json.RawMessage, fields []string) json.RawMessage {
+	var profile map[string]json.RawMessage
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return data
+	}
+
+	requested := map[string]bool{}
+	for _, field := range fields {
+		name, _, _ := strings.Cut(strings.TrimSpace(field), ":")
+		requested[name] = true
+	}
+	for _, field := range optionalFields {
+		if !requested[field] {
+			delete(profile, field)
+		}
+	}
+
+	filtered, err := json.Marshal(profile)
+	if err != nil {
+		return data
+	}
+	return filtered
+}
+
+// optionalFields are the character profile keys only returned when requested
+var optionalFields = []string{
+	"gear", "talents", "guild", "covenant", "raid_progression", "mythic_plus_scores_by_season",
+	"mythic_plus_ranks", "mythic_plus_recent_runs", "mythic_plus_best_runs", "mythic_plus_alternate_runs",
+	"mythic_plus_highest_level_runs", "mythic_plus_weekly_highest_level_runs",
+	"mythic_plus_previous_weekly_highest_level_runs", "previous_mythic_plus_ranks",
+	"raid_achievement_meta", "raid_achievement_curve",
+}
+
+// writeJSON writes a successful JSON response
+func writeJSON(w http.ResponseWriter, data json.RawMessage) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = w.Write(data)
+}
+
+// writeError writes an error response in the format of the API
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"statusCode": status,
+		"error":      http.StatusText(status),
+		"message":    message,
+	})
+}
+
+// load indexes the fixture files
+func (s *Server) load(fsys fs.FS) error {
+	byIdentity := []struct {
+		pattern string
+		target  map[string]json.RawMessage
+	}{
+		{"characters/*.json", s.characters},
+		{"guilds/*.json", s.guilds},
+	}
+	for _, set := range byIdentity {
+		err := readFixtures(fsys, set.pattern, func(name string, data json.RawMessage) error {
+			var identity struct {
+				Name   string `json:"name"`
+				Region string `json:"region"`
+				Realm  string `json:"realm"`
+			}
+			if err := json.Unmarshal(data, &identity); err != nil {
+				return fmt.Errorf("failed to decode fixture %s: %w", name, err)
+			}
+			set.target[key(identity.Region, identity.Realm, identity.Name)] = data
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	byRegion := []struct {
+		pattern string
+		target  map[string]json.RawMessage
+	}{
+		{"connected-realms/*.json", s.realms},
+		{"affixes/*.json", s.affixes},
+	}
+	for _, set := range byRegion {
+		err := readFixtures(fsys, set.pattern, func(name string, data json.RawMessage) error {
+			set.target[strings.TrimSuffix(path.Base(name), ".json")] = data
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFixtures calls fn with the contents of every valid JSON fixture matching pattern
+func readFixtures(fsys fs.FS, pattern string, fn func(name string, data json.RawMessage) error) error {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("failed to read fixture %s: %w", name, err)
+		}
+		if !json.Valid(data) {
+			return fmt.Errorf("fixture %s is not valid JSON", name)
+		}
+		if err := fn(name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// key identifies a character or guild case-insensitively, accepting realm names or slugs
+func key(region, realm, name string) string {
+	return strings.ToLower(region) + "/" + wow.RealmSlug(realm) + "/" + strings.ToLower(name)
+}
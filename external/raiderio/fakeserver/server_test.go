@@ -0,0 +1,208 @@
+package fakeserver_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zokiio/mukabi/external/raiderio"
+	"github.com/zokiio/mukabi/external/raiderio/fakeserver"
+)
+
+const apiKey = "secret-key"
+
+func newServer(t *testing.T) *fakeserver.Server {
+	t.Helper()
+	s, err := fakeserver.NewWithFixtures()
+	if err != nil {
+		t.Fatalf("failed to start fake server: %s", err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+// getJSON requests a path of the server and decodes the response body into a map
+func getJSON(t *testing.T, s *fakeserver.Server, path string) (*http.Response, map[string]json.RawMessage) {
+	t.Helper()
+	resp, err := s.Server.Client().Get(s.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s: %s", path, err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response of %s: %s", path, err)
+	}
+	return resp, body
+}
+
+func TestCharacterQuery(t *testing.T) {
+	s := newServer(t)
+	client := s.Client(apiKey)
+
+	profile, err := client.FetchCharacterProfile("EU", "Twisting Nether", "zoki", raiderio.WithFields(raiderio.FieldMythicPlusScoresBySeason))
+	if err != nil {
+		t.Fatalf("FetchCharacterProfile() error = %s", err)
+	}
+	if profile.Name != "Zoki" || len(profile.MythicPlusScoresBySeason) == 0 {
+		t.Errorf("FetchCharacterProfile() = %s with %d seasons, want Zoki with scores", profile.Name, len(profile.MythicPlusScoresBySeason))
+	}
+
+	requests := s.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("Requests() = %d requests, want 1", len(requests))
+	}
+	want := map[string]string{
+		"region":     "EU",
+		"realm":      "Twisting Nether",
+		"name":       "zoki",
+		"fields":     raiderio.FieldMythicPlusScoresBySeason,
+		"access_key": apiKey,
+	}
+	for param, value := range want {
+		if got := requests[0].Query.Get(param); got != value {
+			t.Errorf("query %s = %q, want %q", param, got, value)
+		}
+	}
+	if requests[0].Path != "/api/v1/characters/profile" || requests[0].Status != http.StatusOK {
+		t.Errorf("request = %s %d, want /api/v1/characters/profile 200", requests[0].Path, requests[0].Status)
+	}
+
+	// Unknown characters fail like the API
+	_, err = client.FetchCharacterProfile("eu", "twisting-nether", "unknown")
+	var statusErr *raiderio.StatusError
+	if !errors.As(err, &statusErr) || !statusErr.NotFound() {
+		t.Errorf("FetchCharacterProfile() of unknown character = %v, want not found", err)
+	}
+}
+
+func TestCharacterFields(t *testing.T) {
+	s := newServer(t)
+
+	tests := []struct {
+		name   string
+		fields string
+		want   bool
+	}{
+		{"none", "", false},
+		{"other", "gear", false},
+		{"qualified", "gear,mythic_plus_scores_by_season:current", true},
+		{"plain", "mythic_plus_scores_by_season", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body := getJSON(t, s, "/api/v1/characters/profile?region=eu&realm=twisting-nether&name=Zoki&fields="+tt.fields)
+			if _, ok := body["mythic_plus_scores_by_season"]; ok != tt.want {
+				t.Errorf("mythic_plus_scores_by_season included = %t, want %t", ok, tt.want)
+			}
+			if _, ok := body["name"]; !ok {
+				t.Error("name missing from filtered profile")
+			}
+		})
+	}
+}
+
+func TestAccessKeyRedaction(t *testing.T) {
+	s := newServer(t)
+	s.FailNext(http.StatusInternalServerError, 1)
+
+	_, err := s.Client(apiKey).FetchCharacterProfile("eu", "twisting-nether", "Zoki")
+	var statusErr *raiderio.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("FetchCharacterProfile() = %v, want status 500", err)
+	}
+	if strings.Contains(err.Error(), apiKey) {
+		t.Errorf("error %q contains the access key", err)
+	}
+	if !strings.Contains(statusErr.URL, "access_key=REDACTED") {
+		t.Errorf("StatusError.URL = %q, want redacted access key", statusErr.URL)
+	}
+
+	// The server still received the real key
+	if got := s.Requests()[0].Query.Get("access_key"); got != apiKey {
+		t.Errorf("received access_key = %q, want %q", got, apiKey)
+	}
+}
+
+func TestConnectedRealmsCache(t *testing.T) {
+	s := newServer(t)
+	client := s.Client(apiKey)
+
+	for _, query := range []string{"twis", "raven", ""} {
+		realms, err := client.FetchConnectedRealms("eu", query)
+		if err != nil {
+			t.Fatalf("FetchConnectedRealms(%q) error = %s", query, err)
+		}
+		if len(realms) == 0 {
+			t.Errorf("FetchConnectedRealms(%q) returned no realms", query)
+		}
+	}
+	if n := s.RequestCount("/api/connected-realms"); n != 1 {
+		t.Errorf("connected realm requests = %d, want 1", n)
+	}
+
+	// Other regions are cached separately
+	if _, err := client.FetchConnectedRealms("us", "area"); err != nil {
+		t.Fatalf("FetchConnectedRealms(us) error = %s", err)
+	}
+	if n := s.RequestCount("/api/connected-realms"); n != 2 {
+		t.Errorf("connected realm requests = %d, want 2", n)
+	}
+}
+
+func TestRateLimitNext(t *testing.T) {
+	s := newServer(t)
+	s.RateLimitNext(1, 2*time.Second)
+
+	resp, _ := getJSON(t, s, "/api/connected-realms?region=eu")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+
+	resp, _ = getJSON(t, s, "/api/connected-realms?region=eu")
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status after rate limit = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	s := newServer(t)
+	client := s.Client(apiKey)
+	s.FailNext(http.StatusServiceUnavailable, 2)
+
+	for i := range 3 {
+		_, err := client.FetchCharacterProfile("eu", "twisting-nether", "Zoki")
+		if fail := i < 2; (err != nil) != fail {
+			t.Errorf("request %d error = %v, want failure %t", i, err, fail)
+		}
+	}
+
+	var statuses []int
+	for _, r := range s.Requests() {
+		statuses = append(statuses, r.Status)
+	}
+	want := []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}
+	if !slices.Equal(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+
+	// Negative counts fail until cleared
+	s.FailNext(http.StatusBadGateway, -1)
+	for range 3 {
+		if _, err := client.FetchCharacterProfile("eu", "twisting-nether", "Zoki"); err == nil {
+			t.Error("request succeeded while failing until cleared")
+		}
+	}
+	s.ClearFaults()
+	if _, err := client.FetchCharacterProfile("eu", "twisting-nether", "Zoki"); err != nil {
+		t.Errorf("request after ClearFaults() error = %s", err)
+	}
+}
@@ -4,41 +4,75 @@ package raiderio
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/topi314/tint"
+	"github.com/zokiio/mukabi/internal/log"
+	"github.com/zokiio/mukabi/internal/metrics"
+	"github.com/zokiio/mukabi/internal/wow"
 )
 
 const (
 	defaultAPIURL     = "https://raider.io/api"
 	defaultAPIVersion = "v1"
-	defaultCacheTTL   = 3600 // 1 hour TTL
+	defaultCacheTTL   = time.Hour
+	defaultTimeout    = 10 * time.Second
 )
 
+// logger returns the logger of the raiderio package, configurable as "raiderio" in [log.levels]
+func logger() *slog.Logger {
+	return log.Named("raiderio")
+}
+
 // Client represents a RaiderIO API client with caching capabilities.
 type Client struct {
 	apiURL     string
-	apiKey     string
 	apiVersion string
-	cache      cacheConfig
-	timeout    int
+	httpClient *http.Client
 	cacheStore *sync.Map
+
+	mu     sync.RWMutex // Guards settings that can be changed at runtime
+	apiKey string
+	cache  cacheConfig
 }
 
 type cacheConfig struct {
 	enabled bool
-	ttl     int
+	ttl     time.Duration
 	backend string
 }
 
+// cacheEntry is a cached API response with its expiry time
+type cacheEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// ClientOption configures optional client settings
+type ClientOption func(*Client)
+
+// WithAPIURL sets the base URL of the API, e.g. to use a local fake server
+func WithAPIURL(apiURL string) ClientOption {
+	return func(c *Client) {
+		c.apiURL = strings.TrimSuffix(apiURL, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
 // New creates a new RaiderIO client with the given API key.
-func New(apiKey string) *Client {
-	return &Client{
+func New(apiKey string, opts ...ClientOption) *Client {
+	c := &Client{
 		apiURL:     defaultAPIURL,
 		apiKey:     apiKey,
 		apiVersion: defaultAPIVersion,
@@ -47,101 +81,117 @@ func New(apiKey string) *Client {
 			ttl:     defaultCacheTTL,
 			backend: "in-memory",
 		},
-		timeout:    10,
+		httpClient: &http.Client{Timeout: defaultTimeout},
 		cacheStore: &sync.Map{},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetAPIKey replaces the API key used for subsequent requests
+func (c *Client) SetAPIKey(apiKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.apiKey = apiKey
 }
 
-// filterRealms filters the realms based on the provided query string
-// It returns a slice of FilteredRealm that match the query
-func filterRealms(realms []FilteredRealm, query string) []FilteredRealm {
-	var filtered []FilteredRealm
+// SetCacheTTL changes how long responses are cached. Entries cached earlier keep their expiry.
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.ttl = ttl
+}
 
-	// If query is empty, return all realms
-	if query == "" {
-		return realms
-	}
+// settings returns a consistent snapshot of the runtime settings
+func (c *Client) settings() (string, cacheConfig) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.apiKey, c.cache
+}
 
-	// Apply the filter: match realms where the realm name contains the query
-	for _, realm := range realms {
-		if strings.Contains(strings.ToLower(realm.Realm), strings.ToLower(query)) {
-			filtered = append(filtered, realm)
-		}
+// loadCache returns cached data for a key if present and not expired
+func (c *Client) loadCache(key string) ([]byte, bool) {
+	value, found := c.cacheStore.Load(key)
+	if !found {
+		return nil, false
 	}
+	entry := value.(cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.cacheStore.Delete(key)
+		return nil, false
+	}
+	return entry.data, true
+}
 
-	return filtered
+// storeCache caches data for a key using the current TTL
+func (c *Client) storeCache(key string, data []byte) {
+	_, cache := c.settings()
+	if !cache.enabled || cache.ttl <= 0 {
+		return
+	}
+	c.cacheStore.Store(key, cacheEntry{data: data, expires: time.Now().Add(cache.ttl)})
 }
 
 // FetchConnectedRealms fetches connected realms from the RaiderIO API and caches the result
-// It filters the realms based on the provided query string and returns the filtered list
+// It searches the realms for the provided query string and returns the ranked matches
 func (c *Client) FetchConnectedRealms(region string, query string) ([]FilteredRealm, error) {
+	if _, err := wow.ParseRegion(region); err != nil {
+		return nil, fmt.Errorf("error fetching connected realms: %w", err)
+	}
+
 	// Cache key based on the region
 	cacheKey := fmt.Sprintf("connected_realms_%s", region)
 
 	// Check cache first
-	cachedData, found := c.cacheStore.Load(cacheKey)
+	cachedData, found := c.loadCache(cacheKey)
+	metrics.ObserveRaiderIOCache("connected-realms", found)
 	if found {
 		// If cache hit, unmarshal and return the filtered realms
 		var realms []FilteredRealm
-		if err := json.Unmarshal(cachedData.([]byte), &realms); err != nil {
-			slog.Error("Failed to unmarshal cached data", tint.Err(err))
+		if err := json.Unmarshal(cachedData, &realms); err != nil {
+			logger().Error("Failed to unmarshal cached data", tint.Err(err))
 			return nil, fmt.Errorf("error unmarshaling cached data: %w", err)
 		}
-		// Apply query filtering
-		filteredRealms := filterRealms(realms, query)
-		return filteredRealms, nil
+		// Apply query search
+		return SearchRealms(realms, query), nil
 	}
 
 	// If no cache hit, fetch data from the API
-	url := fmt.Sprintf("%s/connected-realms?region=%s&realm=all", c.apiURL, region)
-	resp, err := http.Get(url)
+	req := c.newRequest("connected-realms").set("region", region).set("realm", "all")
+	body, err := c.do(req)
 	if err != nil {
-		slog.Error("Failed to make API request", tint.Err(err))
-		return nil, fmt.Errorf("error making API request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		slog.Error("Received non-OK status code", slog.Int("status_code", resp.StatusCode))
-		return nil, fmt.Errorf("error: received non-OK status code %d", resp.StatusCode)
+		return nil, err
 	}
 
 	// Decode API response
 	var apiResponse ConnectedRealms
-	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		slog.Error("Failed to decode API response", tint.Err(err))
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		logger().Error("Failed to decode API response", tint.Err(err))
 		return nil, fmt.Errorf("error decoding API response: %w", err)
 	}
 
-	// Map the realms to FilteredRealm
-	var filteredRealms []FilteredRealm
-	for _, realm := range apiResponse.RealmListing.Realms {
-		for _, connectedRealm := range realm.ConnectedRealms {
-			filteredRealms = append(filteredRealms, FilteredRealm{
-				Region: region,
-				Realm:  connectedRealm.Name,
-				Slug:   connectedRealm.Slug,
-			})
-		}
-	}
+	filteredRealms := apiResponse.Filtered(region)
 
 	// Cache the fetched data
 	dataToCache, err := json.Marshal(filteredRealms)
 	if err != nil {
-		slog.Error("Failed to marshal data for cache", tint.Err(err))
+		logger().Error("Failed to marshal data for cache", tint.Err(err))
 		return nil, fmt.Errorf("error marshaling data for cache: %w", err)
 	}
 
-	c.cacheStore.Store(cacheKey, dataToCache)
+	c.storeCache(cacheKey, dataToCache)
 
-	filteredRealms = filterRealms(filteredRealms, query)
-	return filteredRealms, nil
+	return SearchRealms(filteredRealms, query), nil
 }
 
+// FilteredRealm is the condensed realm information used for realm search
 type FilteredRealm struct {
-	Region string `json:"region"`
-	Realm  string `json:"realm"`
-	Slug   string `json:"slug"`
+	Region  string `json:"region"`
+	Realm   string `json:"realm"`
+	Slug    string `json:"slug"`
+	AltName string `json:"alt_name,omitempty"` // Alternative realm name, e.g. for localized realms
 }
 
 type CharacterProfileReq struct {
@@ -184,54 +234,33 @@ func WithFields(fields ...string) FetchCharacterOption {
 }
 
 func (c *Client) FetchCharacterProfile(region, realm, character string, opts ...FetchCharacterOption) (*CharacterProfile, error) {
-	endpoint := fmt.Sprintf("%s/%s/characters/profile", c.apiURL, c.apiVersion)
-	params := fmt.Sprintf("?access_key=%s&region=%s&realm=%s&name=%s", c.apiKey, region, realm, character)
-
-	cfg := &fetchCharacterConfig{}
-	for _, opt := range opts {
-		opt(cfg)
-	}
-	if len(cfg.fields) > 0 {
-		params += "&fields=" + strings.Join(cfg.fields, ",")
-	}
-
-	fullURL := endpoint + params
-	slog.Debug("Fetching character profile from URL", slog.String("url", fullURL))
-
-	resp, err := http.Get(fullURL)
-	if err != nil {
-		slog.Error("Failed to make API request", tint.Err(err))
-		return nil, fmt.Errorf("error making API request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		slog.Error("Received non-OK status code", slog.Int("status_code", resp.StatusCode))
-		return nil, fmt.Errorf("received status code: %d", resp.StatusCode)
-	}
+	req := c.newVersionedRequest("characters/profile").
+		set("region", region).
+		set("realm", realm).
+		set("name", character).
+		set("fields", joinFields(ApplyOptions(opts...)))
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.do(req)
 	if err != nil {
-		slog.Error("Failed to read response body", tint.Err(err))
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return nil, err
 	}
 
 	var characterProfile CharacterProfile
 	if err := json.Unmarshal(body, &characterProfile); err != nil {
-		slog.Error("Failed to unmarshal character profile", tint.Err(err))
+		logger().Error("Failed to unmarshal character profile", tint.Err(err))
 		return nil, fmt.Errorf("error unmarshaling character profile: %w", err)
 	}
 
 	characterProfile.Gender = strings.ToTitle(characterProfile.Gender)
 	if characterProfile.ThumbnailURL != "" {
 		if _, err := url.ParseRequestURI(characterProfile.ThumbnailURL); err != nil {
-			slog.Warn("Invalid thumbnail URL", slog.String("url", characterProfile.ThumbnailURL))
+			logger().Warn("Invalid thumbnail URL", slog.String("url", characterProfile.ThumbnailURL))
 			characterProfile.ThumbnailURL = "" // Clear it to prevent invalid embeds
 		}
 	}
 	if characterProfile.ProfileBanner != "" {
 		if _, err := url.ParseRequestURI(characterProfile.ProfileBanner); err != nil {
-			slog.Warn("Invalid thumbnail URL", slog.String("url", characterProfile.ProfileBanner))
+			logger().Warn("Invalid thumbnail URL", slog.String("url", characterProfile.ProfileBanner))
 			characterProfile.ProfileBanner = "" // Clear it to prevent invalid embeds
 		}
 	}
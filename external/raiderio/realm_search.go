@@ -0,0 +1,131 @@
+// Package raiderio provides integration with the Raider.IO API
+package raiderio
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zokiio/mukabi/internal/wow"
+)
+
+// Match ranks used to order realm search results, lower is better
+const (
+	rankExact = iota
+	rankPrefix
+	rankWordPrefix
+	rankContains
+	rankFuzzy
+	rankNone
+)
+
+// rankRealmName returns how well a folded query matches a folded name and the edit distance for fuzzy matches
+func rankRealmName(name, query string) (rank int, distance int) {
+	switch {
+	case name == "":
+		return rankNone, 0
+	case name == query:
+		return rankExact, 0
+	case strings.HasPrefix(name, query):
+		return rankPrefix, 0
+	case strings.Contains(" "+name, " "+query):
+		return rankWordPrefix, 0
+	case strings.Contains(name, query):
+		return rankContains, 0
+	}
+
+	// Compare against the start of the name to tolerate typos while the user is still typing
+	prefix := []rune(name)
+	if q := len([]rune(query)); len(prefix) > q {
+		prefix = prefix[:q]
+	}
+	distance = levenshtein([]rune(query), prefix)
+	if distance <= maxTypos(query) {
+		return rankFuzzy, distance
+	}
+	return rankNone, 0
+}
+
+// maxTypos returns the number of edits tolerated for a query of the given length
+func maxTypos(query string) int {
+	n := len([]rune(query))
+	switch {
+	case n < 3:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// levenshtein returns the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// SearchRealms returns the realms matching the query on name, slug or alternative name,
+// ranked with exact and prefix matches first, followed by substring and close matches.
+// Query and names are compared folded with [wow.FoldRealmName].
+func SearchRealms(realms []FilteredRealm, query string) []FilteredRealm {
+	type rankedRealm struct {
+		realm    FilteredRealm
+		rank     int
+		distance int
+		folded   string
+	}
+
+	query = wow.FoldRealmName(query)
+	ranked := make([]rankedRealm, 0, len(realms))
+	for _, realm := range realms {
+		folded := wow.FoldRealmName(realm.Realm)
+		if query == "" {
+			ranked = append(ranked, rankedRealm{realm: realm, folded: folded})
+			continue
+		}
+
+		best := rankedRealm{realm: realm, rank: rankNone, folded: folded}
+		for _, candidate := range []string{folded, wow.FoldRealmName(realm.Slug), wow.FoldRealmName(realm.AltName)} {
+			rank, distance := rankRealmName(candidate, query)
+			if rank < best.rank || (rank == best.rank && distance < best.distance) {
+				best.rank, best.distance = rank, distance
+			}
+		}
+		if best.rank != rankNone {
+			ranked = append(ranked, best)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].rank != ranked[j].rank {
+			return ranked[i].rank < ranked[j].rank
+		}
+		if ranked[i].distance != ranked[j].distance {
+			return ranked[i].distance < ranked[j].distance
+		}
+		return ranked[i].folded < ranked[j].folded
+	})
+
+	filtered := make([]FilteredRealm, len(ranked))
+	for i, r := range ranked {
+		filtered[i] = r.realm
+	}
+	return filtered
+}
@@ -0,0 +1,65 @@
+package raiderio_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zokiio/mukabi/external/raiderio"
+)
+
+var searchRealms = []raiderio.FilteredRealm{
+	{Region: "eu", Realm: "Twisting Nether", Slug: "twisting-nether"},
+	{Region: "eu", Realm: "Aggra (Português)", Slug: "aggra-portugues"},
+	{Region: "eu", Realm: "Nethersturm", Slug: "nethersturm"},
+	{Region: "eu", Realm: "Silvermoon", Slug: "silvermoon"},
+	{Region: "eu", Realm: "Гордунни", Slug: "gordunni", AltName: "Gordunni"},
+	{Region: "eu", Realm: "Kael'thas", Slug: "kaelthas"},
+	{Region: "eu", Realm: "Die Arguswacht", Slug: "die-arguswacht"},
+	{Region: "eu", Realm: "Die ewige Wacht", Slug: "die-ewige-wacht"},
+}
+
+func TestSearchRealms(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"accents", "Aggra Portugues", []string{"aggra-portugues"}},
+		{"punctuation", "aggra-portugues", []string{"aggra-portugues"}},
+		{"apostrophe", "kaelthas", []string{"kaelthas"}},
+		{"prefix before word prefix", "neth", []string{"nethersturm", "twisting-nether"}},
+		{"word prefix before contains", "wacht", []string{"die-ewige-wacht", "die-arguswacht"}},
+		{"exact before prefix", "nethersturm", []string{"nethersturm"}},
+		{"slug", "twisting-nether", []string{"twisting-nether"}},
+		{"alternative name", "gordun", []string{"gordunni"}},
+		{"typo", "Silvermon", []string{"silvermoon"}},
+		{"typo while typing", "silcer", []string{"silvermoon"}},
+		{"no typos in short queries", "sx", nil},
+		{"no match", "zzzzzz", nil},
+		{"empty query", "", []string{"aggra-portugues", "die-arguswacht", "die-ewige-wacht", "kaelthas", "nethersturm", "silvermoon", "twisting-nether", "gordunni"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, realm := range raiderio.SearchRealms(searchRealms, tt.query) {
+				got = append(got, realm.Slug)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SearchRealms(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRealmsRanksCloserTyposFirst(t *testing.T) {
+	realms := []raiderio.FilteredRealm{
+		{Realm: "Ravenholdt", Slug: "ravenholdt"},
+		{Realm: "Ravencrest", Slug: "ravencrest"},
+	}
+
+	// "ravencrst" is one edit away from "ravencres" and more from "ravenhold"
+	got := raiderio.SearchRealms(realms, "ravencrst")
+	if len(got) == 0 || got[0].Slug != "ravencrest" {
+		t.Errorf("SearchRealms(ravencrst) = %v, want ravencrest first", got)
+	}
+}
@@ -0,0 +1,130 @@
+// Package raiderio provides integration with the Raider.IO API for World of Warcraft character and realm data.
+package raiderio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/topi314/tint"
+	"github.com/zokiio/mukabi/internal/metrics"
+)
+
+// accessKeyParam is the query parameter carrying the API key
+const accessKeyParam = "access_key"
+
+// request builds a Raider.IO API request with a properly escaped query
+type request struct {
+	name     string // Endpoint name used in metrics, e.g. characters/profile
+	endpoint string
+	query    url.Values
+}
+
+// newRequest creates a request for the given endpoint path relative to the API URL
+func (c *Client) newRequest(path string) *request {
+	return &request{
+		name:     path,
+		endpoint: c.apiURL + "/" + path,
+		query:    url.Values{},
+	}
+}
+
+// newVersionedRequest creates an authenticated request for a path of the versioned API
+func (c *Client) newVersionedRequest(path string) *request {
+	apiKey, _ := c.settings()
+	r := &request{
+		name:     path,
+		endpoint: c.apiURL + "/" + c.apiVersion + "/" + path,
+		query:    url.Values{},
+	}
+	if apiKey != "" {
+		r.query.Set(accessKeyParam, apiKey)
+	}
+	return r
+}
+
+// set sets a query parameter, skipping empty values
+func (r *request) set(key, value string) *request {
+	if value != "" {
+		r.query.Set(key, value)
+	}
+	return r
+}
+
+// URL returns the full request URL including the access key
+func (r *request) URL() string {
+	if len(r.query) == 0 {
+		return r.endpoint
+	}
+	return r.endpoint + "?" + r.query.Encode()
+}
+
+// String returns the request URL with the access key redacted, safe for logs and errors
+func (r *request) String() string {
+	return RedactURL(r.URL())
+}
+
+// RedactURL returns rawURL with the value of the access key parameter replaced.
+// URLs that cannot be parsed are redacted entirely.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "[invalid url]"
+	}
+
+	query := u.Query()
+	if !query.Has(accessKeyParam) {
+		return rawURL
+	}
+	query.Set(accessKeyParam, "REDACTED")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// redactError removes the access key from URLs embedded in errors of the HTTP client
+func redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = RedactURL(urlErr.URL)
+	}
+	return err
+}
+
+// do performs a GET request and returns the response body of a successful response
+func (c *Client) do(r *request) ([]byte, error) {
+	logger().Debug("Sending Raider.IO request", slog.String("url", r.String()))
+
+	resp, err := c.httpClient.Get(r.URL())
+	if err != nil {
+		err = redactError(err)
+		metrics.ObserveRaiderIORequest(r.name, 0)
+		logger().Error("Failed to make API request", slog.String("url", r.String()), tint.Err(err))
+		return nil, fmt.Errorf("error making API request: %w", err)
+	}
+	defer resp.Body.Close()
+	metrics.ObserveRaiderIORequest(r.name, resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK {
+		logger().Error("Received non-OK status code",
+			slog.String("url", r.String()),
+			slog.Int("status_code", resp.StatusCode),
+		)
+		return nil, &StatusError{StatusCode: resp.StatusCode, URL: r.String()}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger().Error("Failed to read response body", slog.String("url", r.String()), tint.Err(err))
+		return nil, fmt.Errorf("error reading response body of %s: %w", r, err)
+	}
+	return body, nil
+}
+
+// joinFields joins profile fields for the fields query parameter
+func joinFields(fields []string) string {
+	return strings.Join(fields, ",")
+}
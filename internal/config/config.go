@@ -2,18 +2,57 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
 
 	"github.com/BurntSushi/toml"
 	"github.com/zokiio/mukabi/service/bot"
 )
 
-// Load reads and parses the configuration file at the given path
+// Load reads and parses the configuration file at the given path.
+// Values are resolved in order of precedence: MUKABI_*_FILE environment variables,
+// MUKABI_* environment variables, the configuration file, and built-in defaults.
+// A missing file is only an error if no key is set through the environment.
 func Load(path string) (*bot.Config, error) {
+	return load(path, os.LookupEnv)
+}
+
+// load reads the configuration file and applies the environment variables returned by lookup
+func load(path string, lookup func(string) (string, bool)) (*bot.Config, error) {
+	config, fileErr := decodeFile(path)
+	if fileErr != nil && !errors.Is(fileErr, fs.ErrNotExist) {
+		return nil, fileErr
+	}
+
+	applied, err := applyEnv(config, lookup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply environment overrides: %w", err)
+	}
+	if fileErr != nil && applied == 0 {
+		return nil, fileErr
+	}
+
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Development mode always logs at debug level
+	if config.Bot.DevMode {
+		config.Log.Level = slog.LevelDebug
+	}
+
+	return config, nil
+}
+
+// decodeFile decodes the configuration file at the given path.
+// If the file does not exist, an empty configuration is returned along with the error.
+func decodeFile(path string) (*bot.Config, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open config file %s: %w", path, err)
+		return &bot.Config{}, fmt.Errorf("failed to open config file %s: %w", path, err)
 	}
 	defer file.Close()
 
@@ -21,11 +60,9 @@ func Load(path string) (*bot.Config, error) {
 	if _, err := toml.NewDecoder(file).Decode(&config); err != nil {
 		return nil, fmt.Errorf("failed to decode config file %s: %w", path, err)
 	}
-
-	if err := validateConfig(config); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+	if config == nil {
+		config = &bot.Config{}
 	}
-
 	return config, nil
 }
 
@@ -39,12 +76,20 @@ func validateConfig(cfg *bot.Config) error {
 		return fmt.Errorf("bot token is required")
 	}
 
+	if cfg.Bot.DevMode && len(cfg.Bot.GuildIDs) == 0 {
+		return fmt.Errorf("dev mode requires at least one guild ID")
+	}
+
+	if err := cfg.Log.Validate(); err != nil {
+		return err
+	}
+
 	if cfg.Database.Driver == "" {
 		return fmt.Errorf("database driver is required")
 	}
 
-	if cfg.Database.Database == "" {
-		return fmt.Errorf("database name/path is required")
+	if err := cfg.Database.Validate(); err != nil {
+		return err
 	}
 
 	return nil
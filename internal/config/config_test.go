@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testConfig = `
+[bot]
+token = 'file-token'
+
+[database]
+driver = 'postgres'
+database = 'mukabi'
+`
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatalf("failed to write config: %s", err)
+	}
+
+	cfg, err := load(path, env(nil))
+	if err != nil {
+		t.Fatalf("load() error = %s", err)
+	}
+	if cfg.Bot.Token != "file-token" {
+		t.Errorf("token = %q, want file-token", cfg.Bot.Token)
+	}
+
+	// Environment variables override the file
+	cfg, err = load(path, env(map[string]string{"MUKABI_BOT_TOKEN": "env-token"}))
+	if err != nil {
+		t.Fatalf("load() error = %s", err)
+	}
+	if cfg.Bot.Token != "env-token" || cfg.Database.Database != "mukabi" {
+		t.Errorf("token = %q, database = %q, want env-token and mukabi", cfg.Bot.Token, cfg.Database.Database)
+	}
+
+	// The merged configuration is validated
+	if _, err := load(path, env(map[string]string{"MUKABI_BOT_TOKEN": ""})); err == nil {
+		t.Error("load() with an empty token succeeded, want error")
+	}
+}
+
+func TestLoadWithoutFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.toml")
+
+	if _, err := load(path, env(nil)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("load() without file and environment = %v, want not exist", err)
+	}
+
+	cfg, err := load(path, env(map[string]string{
+		"MUKABI_BOT_TOKEN":         "env-token",
+		"MUKABI_DATABASE_DRIVER":   "postgres",
+		"MUKABI_DATABASE_DATABASE": "mukabi",
+	}))
+	if err != nil {
+		t.Fatalf("load() from the environment only error = %s", err)
+	}
+	if cfg.Bot.Token != "env-token" {
+		t.Errorf("token = %q, want env-token", cfg.Bot.Token)
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("[bot\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %s", err)
+	}
+
+	// A broken file is never replaced by the environment
+	if _, err := load(path, env(map[string]string{"MUKABI_BOT_TOKEN": "env-token"})); err == nil {
+		t.Error("load() with an invalid file succeeded, want error")
+	}
+}
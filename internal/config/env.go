@@ -0,0 +1,175 @@
+// Package config provides configuration loading functionality
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is the prefix of all environment variables overriding configuration keys
+const EnvPrefix = "MUKABI"
+
+// fileSuffix is appended to an environment variable name to read its value from a file
+const fileSuffix = "_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv overrides configuration values from environment variables.
+// Every key is addressable as MUKABI_<SECTION>_<KEY> derived from its toml tag, e.g.
+// MUKABI_BOT_TOKEN or MUKABI_DATABASE_PASSWORD. Appending _FILE reads the value from
+// the named file instead, which takes precedence over the plain variable.
+// It returns the number of keys that were overridden.
+func applyEnv(cfg any, lookup func(string) (string, bool)) (int, error) {
+	return applyEnvStruct(reflect.ValueOf(cfg).Elem(), EnvPrefix, lookup)
+}
+
+// applyEnvStruct walks the fields of a struct and applies matching environment variables
+func applyEnvStruct(v reflect.Value, prefix string, lookup func(string) (string, bool)) (int, error) {
+	var applied int
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name := prefix + "_" + strings.ToUpper(tag)
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && !isTextUnmarshaler(fv) {
+			n, err := applyEnvStruct(fv, name, lookup)
+			if err != nil {
+				return applied, err
+			}
+			applied += n
+			continue
+		}
+
+		value, ok, err := lookupEnv(name, lookup)
+		if err != nil {
+			return applied, err
+		}
+		if !ok {
+			continue
+		}
+		if err := setValue(fv, value); err != nil {
+			return applied, fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// lookupEnv returns the value of an environment variable, preferring the _FILE variant
+func lookupEnv(name string, lookup func(string) (string, bool)) (string, bool, error) {
+	if path, ok := lookup(name + fileSuffix); ok && path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to read %s%s: %w", name, fileSuffix, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	}
+	value, ok := lookup(name)
+	return value, ok, nil
+}
+
+// isTextUnmarshaler reports whether the addressable value implements encoding.TextUnmarshaler
+func isTextUnmarshaler(v reflect.Value) bool {
+	_, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// setValue parses a string into the given value based on its type.
+// Slices are read as comma-separated lists and maps as comma-separated key=value pairs.
+func setValue(v reflect.Value, value string) error {
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var parts []string
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setValue(slice.Index(i), part); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			key, val, ok := strings.Cut(part, "=")
+			if !ok {
+				return fmt.Errorf("invalid map entry %q, expected key=value", part)
+			}
+			k := reflect.New(v.Type().Key()).Elem()
+			if err := setValue(k, strings.TrimSpace(key)); err != nil {
+				return err
+			}
+			e := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(e, strings.TrimSpace(val)); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envConfig covers every kind of value applyEnv supports
+type envConfig struct {
+	Name     string                `toml:"name"`
+	Enabled  bool                  `toml:"enabled"`
+	Count    int                   `toml:"count"`
+	IDs      []uint64              `toml:"ids"`
+	Ratio    float64               `toml:"ratio"`
+	Timeout  time.Duration         `toml:"timeout"`
+	Level    slog.Level            `toml:"level"` // encoding.TextUnmarshaler
+	Levels   map[string]slog.Level `toml:"levels"`
+	Nested   envNested             `toml:"nested"`
+	Untagged string
+	Skipped  string `toml:"-"`
+}
+
+type envNested struct {
+	Token string   `toml:"token,omitempty"`
+	Tags  []string `toml:"tags"`
+}
+
+// env returns a lookup func serving the given variables
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+// writeSecret writes a secret file and returns its path
+func writeSecret(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write secret: %s", err)
+	}
+	return path
+}
+
+func TestApplyEnv(t *testing.T) {
+	secret := writeSecret(t, "from-file\n")
+
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		want    envConfig
+		applied int
+	}{
+		{
+			name: "none",
+			vars: map[string]string{"OTHER_NAME": "x"},
+			want: envConfig{Name: "file"},
+		},
+		{
+			name: "scalars",
+			vars: map[string]string{
+				"MUKABI_NAME":    "env",
+				"MUKABI_ENABLED": "true",
+				"MUKABI_COUNT":   "-3",
+				"MUKABI_RATIO":   "0.5",
+				"MUKABI_TIMEOUT": "1m30s",
+				"MUKABI_LEVEL":   "warn",
+			},
+			want:    envConfig{Name: "env", Enabled: true, Count: -3, Ratio: 0.5, Timeout: 90 * time.Second, Level: slog.LevelWarn},
+			applied: 6,
+		},
+		{
+			name:    "slice",
+			vars:    map[string]string{"MUKABI_IDS": " 1, 2,,3 "},
+			want:    envConfig{Name: "file", IDs: []uint64{1, 2, 3}},
+			applied: 1,
+		},
+		{
+			name:    "map",
+			vars:    map[string]string{"MUKABI_LEVELS": "db=debug, raiderio = error"},
+			want:    envConfig{Name: "file", Levels: map[string]slog.Level{"db": slog.LevelDebug, "raiderio": slog.LevelError}},
+			applied: 1,
+		},
+		{
+			name:    "nested",
+			vars:    map[string]string{"MUKABI_NESTED_TOKEN": "abc", "MUKABI_NESTED_TAGS": "a,b"},
+			want:    envConfig{Name: "file", Nested: envNested{Token: "abc", Tags: []string{"a", "b"}}},
+			applied: 2,
+		},
+		{
+			name:    "empty value",
+			vars:    map[string]string{"MUKABI_NAME": ""},
+			want:    envConfig{},
+			applied: 1,
+		},
+		{
+			name:    "file takes precedence",
+			vars:    map[string]string{"MUKABI_NESTED_TOKEN": "plain", "MUKABI_NESTED_TOKEN_FILE": secret},
+			want:    envConfig{Name: "file", Nested: envNested{Token: "from-file"}},
+			applied: 1,
+		},
+		{
+			name:    "empty file variable is ignored",
+			vars:    map[string]string{"MUKABI_NESTED_TOKEN": "plain", "MUKABI_NESTED_TOKEN_FILE": ""},
+			want:    envConfig{Name: "file", Nested: envNested{Token: "plain"}},
+			applied: 1,
+		},
+		{
+			name: "untagged and skipped fields",
+			vars: map[string]string{"MUKABI_UNTAGGED": "x", "MUKABI_SKIPPED": "x", "MUKABI_-": "x"},
+			want: envConfig{Name: "file"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := envConfig{Name: "file"}
+			applied, err := applyEnv(&cfg, env(tt.vars))
+			if err != nil {
+				t.Fatalf("applyEnv() error = %s", err)
+			}
+			if !reflect.DeepEqual(cfg, tt.want) {
+				t.Errorf("applyEnv() = %+v, want %+v", cfg, tt.want)
+			}
+			if applied != tt.applied {
+				t.Errorf("applyEnv() applied %d keys, want %d", applied, tt.applied)
+			}
+		})
+	}
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		want string
+	}{
+		{"bool", map[string]string{"MUKABI_ENABLED": "maybe"}, "MUKABI_ENABLED"},
+		{"int", map[string]string{"MUKABI_COUNT": "many"}, "MUKABI_COUNT"},
+		{"slice element", map[string]string{"MUKABI_IDS": "1,x"}, "MUKABI_IDS"},
+		{"duration", map[string]string{"MUKABI_TIMEOUT": "10"}, "MUKABI_TIMEOUT"},
+		{"text unmarshaler", map[string]string{"MUKABI_LEVEL": "loud"}, "MUKABI_LEVEL"},
+		{"map entry", map[string]string{"MUKABI_LEVELS": "db"}, "expected key=value"},
+		{"map value", map[string]string{"MUKABI_LEVELS": "db=loud"}, "MUKABI_LEVELS"},
+		{"missing file", map[string]string{"MUKABI_NAME_FILE": filepath.Join(t.TempDir(), "missing")}, "MUKABI_NAME_FILE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg envConfig
+			_, err := applyEnv(&cfg, env(tt.vars))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("applyEnv() error = %v, want error mentioning %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEnvUnsupportedType(t *testing.T) {
+	cfg := struct {
+		Channel chan int `toml:"channel"`
+	}{}
+	if _, err := applyEnv(&cfg, env(map[string]string{"MUKABI_CHANNEL": "x"})); err == nil {
+		t.Error("applyEnv() on a channel succeeded, want error")
+	}
+}
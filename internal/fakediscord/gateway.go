@@ -0,0 +1,171 @@
+// Package fakediscord provides a local stand-in for the Discord gateway and REST API.
+package fakediscord
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/gorilla/websocket"
+)
+
+// heartbeatInterval is sent in HELLO, long enough to not matter in tests
+const heartbeatInterval = 45 * time.Second
+
+// conn is a gateway connection of a bot shard
+type conn struct {
+	ws *websocket.Conn
+
+	mu  sync.Mutex // Serializes writes and the sequence number
+	seq int
+}
+
+// message is a gateway payload
+type message struct {
+	Op gateway.Opcode    `json:"op"`
+	S  int               `json:"s,omitempty"`
+	T  gateway.EventType `json:"t,omitempty"`
+	D  any               `json:"d"`
+}
+
+// send writes a non-dispatch payload
+func (c *conn) send(op gateway.Opcode, d any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ws.WriteJSON(message{Op: op, D: d})
+}
+
+// dispatch writes a dispatch event with the next sequence number
+func (c *conn) dispatch(eventType gateway.EventType, d any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	return c.ws.WriteJSON(message{Op: gateway.OpcodeDispatch, S: c.seq, T: eventType, D: d})
+}
+
+// Dispatch sends a gateway event to all connected shards
+func (s *Server) Dispatch(eventType gateway.EventType, d any) error {
+	s.mu.Lock()
+	conns := slices.Clone(s.conns)
+	s.mu.Unlock()
+
+	if len(conns) == 0 {
+		return fmt.Errorf("no gateway connection")
+	}
+	for _, c := range conns {
+		if err := c.dispatch(eventType, d); err != nil {
+			return fmt.Errorf("failed to dispatch %s: %w", eventType, err)
+		}
+	}
+	return nil
+}
+
+// handleGateway serves a gateway connection: HELLO, heartbeat ACKs, and READY followed by
+// GUILD_CREATE for every guild once the bot identifies
+func (s *Server) handleGateway(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &conn{ws: ws}
+	defer func() {
+		s.mu.Lock()
+		s.conns = slices.DeleteFunc(s.conns, func(other *conn) bool { return other == c })
+		s.mu.Unlock()
+		_ = ws.Close()
+	}()
+
+	if err := c.send(gateway.OpcodeHello, map[string]any{"heartbeat_interval": heartbeatInterval.Milliseconds()}); err != nil {
+		return
+	}
+
+	for {
+		var msg struct {
+			Op gateway.Opcode  `json:"op"`
+			D  json.RawMessage `json:"d"`
+		}
+		if err := ws.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		switch msg.Op {
+		case gateway.OpcodeHeartbeat:
+			err = c.send(gateway.OpcodeHeartbeatACK, nil)
+		case gateway.OpcodeIdentify, gateway.OpcodeResume:
+			err = s.identify(c)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// identify registers the connection and sends READY and the guilds
+func (s *Server) identify(c *conn) error {
+	s.mu.Lock()
+	s.conns = append(s.conns, c)
+	guilds := slices.Clone(s.guilds)
+	s.mu.Unlock()
+
+	unavailable := make([]map[string]any, len(guilds))
+	for i, guild := range guilds {
+		unavailable[i] = map[string]any{"id": guild.ID, "unavailable": true}
+	}
+
+	if err := c.dispatch(gateway.EventTypeReady, map[string]any{
+		"v":                  gateway.Version,
+		"user":               map[string]any{"id": s.ApplicationID, "username": "mukabi", "discriminator": "0", "bot": true},
+		"guilds":             unavailable,
+		"session_id":         "fake-session",
+		"resume_gateway_url": s.GatewayURL(),
+		"shard":              []int{0, 1},
+		"application":        map[string]any{"id": s.ApplicationID, "flags": 0},
+	}); err != nil {
+		return err
+	}
+
+	for _, guild := range guilds {
+		if err := c.dispatch(gateway.EventTypeGuildCreate, guildPayload(guild)); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.ready:
+	default:
+		close(s.ready)
+	}
+	return nil
+}
+
+// guildPayload returns the GUILD_CREATE payload of a guild
+func guildPayload(guild Guild) map[string]any {
+	return map[string]any{
+		"id":                     guild.ID,
+		"name":                   guild.Name,
+		"owner_id":               guild.ID,
+		"joined_at":              time.Now().Format(time.RFC3339),
+		"member_count":           1,
+		"preferred_locale":       discord.LocaleEnglishUS,
+		"unavailable":            false,
+		"roles":                  []any{},
+		"emojis":                 []any{},
+		"stickers":               []any{},
+		"features":               []any{},
+		"members":                []any{},
+		"channels":               []any{},
+		"threads":                []any{},
+		"voice_states":           []any{},
+		"presences":              []any{},
+		"stage_instances":        []any{},
+		"guild_scheduled_events": []any{},
+		"soundboard_sounds":      []any{},
+	}
+}
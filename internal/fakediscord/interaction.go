@@ -0,0 +1,386 @@
+// Package fakediscord provides a local stand-in for the Discord gateway and REST API.
+package fakediscord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// ResponseKind describes how the bot responded to an interaction
+type ResponseKind string
+
+// Response kinds
+const (
+	ResponseCallback ResponseKind = "callback" // Initial interaction response
+	ResponseEdit     ResponseKind = "edit"     // Edit of the original response, e.g. after deferring
+	ResponseFollowup ResponseKind = "followup" // Followup message
+)
+
+// Message is the message content sent by the bot
+type Message struct {
+	Content    string               `json:"content"`
+	Embeds     []discord.Embed      `json:"embeds"`
+	Flags      discord.MessageFlags `json:"flags"`
+	Components json.RawMessage      `json:"components"`
+	Choices    []Choice             `json:"choices"` // Autocomplete results
+}
+
+// Choice is an autocomplete choice
+type Choice struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+// Ephemeral reports whether the message is only visible to the invoking user
+func (m Message) Ephemeral() bool {
+	return m.Flags.Has(discord.MessageFlagEphemeral)
+}
+
+// CustomIDs returns the custom IDs of all components, e.g. to click a button
+func (m Message) CustomIDs() []string {
+	var ids []string
+	var walk func(raw json.RawMessage)
+	walk = func(raw json.RawMessage) {
+		var components []struct {
+			CustomID   string          `json:"custom_id"`
+			Components json.RawMessage `json:"components"`
+		}
+		if json.Unmarshal(raw, &components) != nil {
+			return
+		}
+		for _, component := range components {
+			if component.CustomID != "" {
+				ids = append(ids, component.CustomID)
+			}
+			if len(component.Components) > 0 {
+				walk(component.Components)
+			}
+		}
+	}
+	walk(m.Components)
+	return ids
+}
+
+// Response is a response of the bot to an interaction
+type Response struct {
+	Kind    ResponseKind
+	Type    discord.InteractionResponseType // Set for callbacks
+	Message Message
+}
+
+// Interaction is an interaction sent to the bot, collecting its responses
+type Interaction struct {
+	ID        snowflake.ID
+	Token     string
+	GuildID   snowflake.ID
+	ChannelID snowflake.ID
+
+	mu        sync.Mutex
+	responded bool
+	responses []Response
+	next      int
+	notify    chan struct{}
+}
+
+// respond marks the interaction as acknowledged, returning false if it already was
+func (i *Interaction) respond() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.responded {
+		return false
+	}
+	i.responded = true
+	return true
+}
+
+// add records a response and wakes up waiters
+func (i *Interaction) add(response Response) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.responses = append(i.responses, response)
+	close(i.notify)
+	i.notify = make(chan struct{})
+}
+
+// Next waits for the next response not returned before
+func (i *Interaction) Next(ctx context.Context) (Response, error) {
+	for {
+		i.mu.Lock()
+		if i.next < len(i.responses) {
+			response := i.responses[i.next]
+			i.next++
+			i.mu.Unlock()
+			return response, nil
+		}
+		notify := i.notify
+		i.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return Response{}, fmt.Errorf("no response to interaction %s: %w", i.ID, ctx.Err())
+		}
+	}
+}
+
+// Last waits for the next response and returns the message the user ends up seeing:
+// after a deferred callback it waits for the edit of the original response.
+func (i *Interaction) Last(ctx context.Context) (Message, error) {
+	for {
+		response, err := i.Next(ctx)
+		if err != nil {
+			return Message{}, err
+		}
+		if response.Kind == ResponseCallback && (response.Type == discord.InteractionResponseTypeDeferredCreateMessage ||
+			response.Type == discord.InteractionResponseTypeDeferredUpdateMessage) {
+			continue
+		}
+		return response.Message, nil
+	}
+}
+
+// Responses returns all responses received so far
+func (i *Interaction) Responses() []Response {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]Response(nil), i.responses...)
+}
+
+// interaction looks up an interaction by token
+func (s *Server) interaction(token string) (*Interaction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	interaction, ok := s.interactions[token]
+	return interaction, ok
+}
+
+// User is the member invoking an interaction
+type User struct {
+	ID          snowflake.ID
+	Username    string
+	Permissions discord.Permissions // Guild permissions, all permissions if zero
+}
+
+// Option is a command option value. Values are sent as string, integer, number or boolean
+// options depending on their Go type.
+type Option struct {
+	Name    string
+	Value   any
+	Focused bool // Marks the option being autocompleted
+}
+
+// SlashCommand describes a slash command invocation
+type SlashCommand struct {
+	GuildID    snowflake.ID
+	ChannelID  snowflake.ID // Generated if zero
+	User       User
+	Locale     discord.Locale // en-US if empty
+	Name       string
+	Subcommand string // Optional subcommand
+	Options    []Option
+}
+
+// UserCommand describes a user context menu command invocation.
+// The contexts of the command are not enforced, as Discord keeps offering commands
+// where they were allowed before their contexts changed.
+type UserCommand struct {
+	GuildID   snowflake.ID // Zero for an invocation in a DM with the bot
+	ChannelID snowflake.ID // Generated if zero
+	User      User
+	Locale    discord.Locale // en-US if empty
+	Name      string
+	Target    User // User the command is invoked on
+}
+
+// SendUserCommand dispatches a user context menu command interaction
+func (s *Server) SendUserCommand(cmd UserCommand) (*Interaction, error) {
+	commandID, ok := s.commandID(cmd.GuildID, cmd.Name)
+	if !ok {
+		return nil, fmt.Errorf("command %q is not registered", cmd.Name)
+	}
+
+	interaction, payload := s.newInteraction(discord.InteractionTypeApplicationCommand, cmd.GuildID, cmd.ChannelID, cmd.User, cmd.Locale)
+	data := map[string]any{
+		"id":        commandID,
+		"name":      cmd.Name,
+		"type":      discord.ApplicationCommandTypeUser,
+		"target_id": cmd.Target.ID,
+		"resolved": map[string]any{
+			"users": map[string]any{
+				cmd.Target.ID.String(): map[string]any{"id": cmd.Target.ID, "username": cmd.Target.Username, "discriminator": "0"},
+			},
+		},
+	}
+	if cmd.GuildID != 0 {
+		data["guild_id"] = cmd.GuildID
+	}
+	payload["data"] = data
+	return interaction, s.Dispatch(gateway.EventTypeInteractionCreate, payload)
+}
+
+// SendSlashCommand dispatches a slash command interaction
+func (s *Server) SendSlashCommand(cmd SlashCommand) (*Interaction, error) {
+	return s.sendCommand(discord.InteractionTypeApplicationCommand, cmd)
+}
+
+// SendAutocomplete dispatches an autocomplete interaction, one option should be focused
+func (s *Server) SendAutocomplete(cmd SlashCommand) (*Interaction, error) {
+	return s.sendCommand(discord.InteractionTypeAutocomplete, cmd)
+}
+
+// SendComponent dispatches a component interaction, e.g. a button click with the given custom ID
+func (s *Server) SendComponent(guildID snowflake.ID, user User, customID string) (*Interaction, error) {
+	interaction, payload := s.newInteraction(discord.InteractionTypeComponent, guildID, 0, user, "")
+	payload["data"] = map[string]any{
+		"custom_id":      customID,
+		"component_type": discord.ComponentTypeButton,
+	}
+	payload["message"] = map[string]any{
+		"id":         s.newID(),
+		"channel_id": interaction.ChannelID,
+		"type":       discord.MessageTypeDefault,
+		"content":    "",
+		"timestamp":  time.Now().Format(time.RFC3339),
+		"author":     map[string]any{"id": s.ApplicationID, "username": "mukabi", "discriminator": "0", "bot": true},
+	}
+	return interaction, s.Dispatch(gateway.EventTypeInteractionCreate, payload)
+}
+
+// sendCommand dispatches an application command or autocomplete interaction
+func (s *Server) sendCommand(interactionType discord.InteractionType, cmd SlashCommand) (*Interaction, error) {
+	commandID, ok := s.commandID(cmd.GuildID, cmd.Name)
+	if !ok {
+		return nil, fmt.Errorf("command %q is not registered", cmd.Name)
+	}
+
+	options := make([]map[string]any, len(cmd.Options))
+	for i, option := range cmd.Options {
+		options[i] = map[string]any{
+			"name":  option.Name,
+			"type":  optionType(option.Value),
+			"value": option.Value,
+		}
+		if option.Focused {
+			options[i]["focused"] = true
+		}
+	}
+	if cmd.Subcommand != "" {
+		options = []map[string]any{{
+			"name":    cmd.Subcommand,
+			"type":    discord.ApplicationCommandOptionTypeSubCommand,
+			"options": options,
+		}}
+	}
+
+	interaction, payload := s.newInteraction(interactionType, cmd.GuildID, cmd.ChannelID, cmd.User, cmd.Locale)
+	data := map[string]any{
+		"id":      commandID,
+		"name":    cmd.Name,
+		"type":    discord.ApplicationCommandTypeSlash,
+		"options": options,
+	}
+	if cmd.GuildID != 0 {
+		data["guild_id"] = cmd.GuildID
+	}
+	payload["data"] = data
+	return interaction, s.Dispatch(gateway.EventTypeInteractionCreate, payload)
+}
+
+// newInteraction registers an interaction and returns its base payload
+func (s *Server) newInteraction(interactionType discord.InteractionType, guildID, channelID snowflake.ID, user User, locale discord.Locale) (*Interaction, map[string]any) {
+	if channelID == 0 {
+		channelID = s.newID()
+	}
+	if locale == "" {
+		locale = discord.LocaleEnglishUS
+	}
+	if user.Username == "" {
+		user.Username = "user"
+	}
+	if user.Permissions == 0 {
+		user.Permissions = discord.PermissionsAll
+	}
+
+	interaction := &Interaction{
+		ID:        s.newID(),
+		GuildID:   guildID,
+		ChannelID: channelID,
+		notify:    make(chan struct{}),
+	}
+	interaction.Token = "token-" + interaction.ID.String()
+
+	s.mu.Lock()
+	s.interactions[interaction.Token] = interaction
+	s.mu.Unlock()
+
+	userPayload := map[string]any{"id": user.ID, "username": user.Username, "discriminator": "0"}
+	payload := map[string]any{
+		"id":             interaction.ID,
+		"application_id": s.ApplicationID,
+		"type":           interactionType,
+		"token":          interaction.Token,
+		"version":        1,
+		"channel_id":     channelID,
+
+		"locale":          locale,
+		"app_permissions": discord.PermissionsAll,
+		"entitlements":    []any{},
+	}
+	if guildID != 0 {
+		payload["channel"] = map[string]any{"id": channelID, "type": discord.ChannelTypeGuildText, "name": "general"}
+		payload["guild_id"] = guildID
+		payload["guild_locale"] = discord.LocaleEnglishUS
+		payload["context"] = discord.InteractionContextTypeGuild
+		payload["member"] = map[string]any{
+			"user":        userPayload,
+			"roles":       []any{},
+			"joined_at":   time.Now().Format(time.RFC3339),
+			"permissions": user.Permissions,
+		}
+	} else {
+		payload["channel"] = map[string]any{"id": channelID, "type": discord.ChannelTypeDM}
+		payload["context"] = discord.InteractionContextTypeBotDM
+		payload["user"] = userPayload
+	}
+	return interaction, payload
+}
+
+// commandID returns the ID of a registered guild or global command by name
+func (s *Server) commandID(guildID snowflake.ID, name string) (snowflake.ID, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Guild commands take precedence, like in the client
+	for _, id := range []snowflake.ID{guildID, 0} {
+		for _, command := range s.commands[id] {
+			if command.Name() == name {
+				return command.ID(), true
+			}
+		}
+		if guildID == 0 {
+			break
+		}
+	}
+	return 0, false
+}
+
+// optionType returns the option type of a value
+func optionType(v any) discord.ApplicationCommandOptionType {
+	switch v.(type) {
+	case bool:
+		return discord.ApplicationCommandOptionTypeBool
+	case int, int32, int64:
+		return discord.ApplicationCommandOptionTypeInt
+	case float32, float64:
+		return discord.ApplicationCommandOptionTypeFloat
+	default:
+		return discord.ApplicationCommandOptionTypeString
+	}
+}
@@ -0,0 +1,346 @@
+// Package fakediscord provides a local stand-in for the Discord gateway and REST API.
+package fakediscord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// ChannelMessage is a message the bot posted to a channel
+type ChannelMessage struct {
+	ChannelID snowflake.ID
+	Message   Message
+}
+
+// restHandler returns the handler of the REST API, recording every request
+func (s *Server) restHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /gateway", s.handleGetGateway)
+	mux.HandleFunc("GET /gateway/bot", s.handleGetGateway)
+
+	mux.HandleFunc("GET /applications/{app}/commands", s.handleGetCommands)
+	mux.HandleFunc("POST /applications/{app}/commands", s.handleCreateCommand)
+	mux.HandleFunc("PUT /applications/{app}/commands", s.handleSetCommands)
+	mux.HandleFunc("PATCH /applications/{app}/commands/{id}", s.handleUpdateCommand)
+	mux.HandleFunc("DELETE /applications/{app}/commands/{id}", s.handleDeleteCommand)
+	mux.HandleFunc("GET /applications/{app}/guilds/{guild}/commands", s.handleGetCommands)
+	mux.HandleFunc("POST /applications/{app}/guilds/{guild}/commands", s.handleCreateCommand)
+	mux.HandleFunc("PUT /applications/{app}/guilds/{guild}/commands", s.handleSetCommands)
+	mux.HandleFunc("PATCH /applications/{app}/guilds/{guild}/commands/{id}", s.handleUpdateCommand)
+	mux.HandleFunc("DELETE /applications/{app}/guilds/{guild}/commands/{id}", s.handleDeleteCommand)
+
+	mux.HandleFunc("POST /interactions/{id}/{token}/callback", s.handleInteractionCallback)
+	mux.HandleFunc("PATCH /webhooks/{app}/{token}/messages/{message}", s.handleEditResponse)
+	mux.HandleFunc("POST /webhooks/{app}/{token}", s.handleFollowup)
+
+	mux.HandleFunc("POST /channels/{channel}/messages", s.handleChannelMessage)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s %s is not supported by the fake", r.Method, r.URL.Path))
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Body:   body,
+			Time:   time.Now(),
+		})
+		s.mu.Unlock()
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleGetGateway(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"url":    s.GatewayURL(),
+		"shards": 1,
+		"session_start_limit": map[string]any{
+			"total":           1000,
+			"remaining":       1000,
+			"reset_after":     0,
+			"max_concurrency": 1,
+		},
+	})
+}
+
+func (s *Server) handleGetCommands(w http.ResponseWriter, r *http.Request) {
+	guildID := pathID(r, "guild")
+
+	s.mu.Lock()
+	commands := slices.Clone(s.commands[guildID])
+	s.mu.Unlock()
+
+	if commands == nil {
+		commands = []discord.ApplicationCommand{}
+	}
+	writeJSON(w, http.StatusOK, commands)
+}
+
+func (s *Server) handleCreateCommand(w http.ResponseWriter, r *http.Request) {
+	guildID := pathID(r, "guild")
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	command, err := s.upsertCommandLocked(guildID, raw, 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, command)
+}
+
+func (s *Server) handleSetCommands(w http.ResponseWriter, r *http.Request) {
+	guildID := pathID(r, "guild")
+
+	var raws []json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raws); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.commands[guildID] = nil
+	commands := make([]discord.ApplicationCommand, 0, len(raws))
+	for _, raw := range raws {
+		command, err := s.upsertCommandLocked(guildID, raw, 0)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		commands = append(commands, command)
+	}
+	writeJSON(w, http.StatusOK, commands)
+}
+
+func (s *Server) handleUpdateCommand(w http.ResponseWriter, r *http.Request) {
+	guildID := pathID(r, "guild")
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	command, err := s.upsertCommandLocked(guildID, raw, pathID(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, command)
+}
+
+func (s *Server) handleDeleteCommand(w http.ResponseWriter, r *http.Request) {
+	guildID, id := pathID(r, "guild"), pathID(r, "id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.commands[guildID])
+	s.commands[guildID] = slices.DeleteFunc(s.commands[guildID], func(c discord.ApplicationCommand) bool {
+		return c.ID() == id
+	})
+	if len(s.commands[guildID]) == before {
+		writeError(w, http.StatusNotFound, "Unknown application command")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// upsertCommandLocked stores a command, replacing the one with the given ID or the same name
+// and type like Discord does. s.mu must be held.
+func (s *Server) upsertCommandLocked(guildID snowflake.ID, raw []byte, id snowflake.ID) (discord.ApplicationCommand, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	if _, ok := fields["type"]; !ok {
+		fields["type"] = discord.ApplicationCommandTypeSlash
+	}
+	// Discord fills in the contexts and installation types when they are omitted
+	if fields["contexts"] == nil {
+		fields["contexts"] = []discord.InteractionContextType{
+			discord.InteractionContextTypeGuild,
+			discord.InteractionContextTypeBotDM,
+			discord.InteractionContextTypePrivateChannel,
+		}
+	}
+	if fields["integration_types"] == nil {
+		fields["integration_types"] = []discord.ApplicationIntegrationType{discord.ApplicationIntegrationTypeGuildInstall}
+	}
+
+	var patch discord.UnmarshalApplicationCommand
+	if err := remarshal(fields, &patch); err != nil {
+		return nil, err
+	}
+
+	commands := s.commands[guildID]
+	index := slices.IndexFunc(commands, func(c discord.ApplicationCommand) bool {
+		if id != 0 {
+			return c.ID() == id
+		}
+		return c.Name() == fields["name"] && c.Type() == patch.ApplicationCommand.Type()
+	})
+	if index >= 0 {
+		id = commands[index].ID()
+	} else if id != 0 {
+		return nil, fmt.Errorf("unknown application command %s", id)
+	} else {
+		id = s.newIDLocked()
+	}
+
+	fields["id"] = id
+	fields["application_id"] = s.ApplicationID
+	fields["version"] = s.newIDLocked()
+	if guildID != 0 {
+		fields["guild_id"] = guildID
+	}
+
+	var command discord.UnmarshalApplicationCommand
+	if err := remarshal(fields, &command); err != nil {
+		return nil, err
+	}
+	if index >= 0 {
+		commands[index] = command.ApplicationCommand
+	} else {
+		s.commands[guildID] = append(commands, command.ApplicationCommand)
+	}
+	return command.ApplicationCommand, nil
+}
+
+func (s *Server) handleInteractionCallback(w http.ResponseWriter, r *http.Request) {
+	var callback struct {
+		Type discord.InteractionResponseType `json:"type"`
+		Data Message                         `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&callback); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	interaction, ok := s.interaction(r.PathValue("token"))
+	if !ok || interaction.ID.String() != r.PathValue("id") {
+		writeError(w, http.StatusNotFound, "Unknown interaction")
+		return
+	}
+	if !interaction.respond() {
+		writeError(w, http.StatusBadRequest, "Interaction has already been acknowledged")
+		return
+	}
+
+	interaction.add(Response{Kind: ResponseCallback, Type: callback.Type, Message: callback.Data})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleEditResponse(w http.ResponseWriter, r *http.Request) {
+	var message Message
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	interaction, ok := s.interaction(r.PathValue("token"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Unknown webhook")
+		return
+	}
+
+	interaction.add(Response{Kind: ResponseEdit, Message: message})
+	writeJSON(w, http.StatusOK, s.messagePayload(interaction.ChannelID, message))
+}
+
+func (s *Server) handleFollowup(w http.ResponseWriter, r *http.Request) {
+	var message Message
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	interaction, ok := s.interaction(r.PathValue("token"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Unknown webhook")
+		return
+	}
+
+	interaction.add(Response{Kind: ResponseFollowup, Message: message})
+	writeJSON(w, http.StatusOK, s.messagePayload(interaction.ChannelID, message))
+}
+
+func (s *Server) handleChannelMessage(w http.ResponseWriter, r *http.Request) {
+	var message Message
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	channelID := pathID(r, "channel")
+
+	s.mu.Lock()
+	s.messages = append(s.messages, ChannelMessage{ChannelID: channelID, Message: message})
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, s.messagePayload(channelID, message))
+}
+
+// messagePayload returns the message object returned for a created or edited message
+func (s *Server) messagePayload(channelID snowflake.ID, message Message) map[string]any {
+	return map[string]any{
+		"id":         s.newID(),
+		"channel_id": channelID,
+		"type":       discord.MessageTypeDefault,
+		"content":    message.Content,
+		"embeds":     message.Embeds,
+		"flags":      message.Flags,
+		"timestamp":  time.Now().Format(time.RFC3339),
+		"author":     map[string]any{"id": s.ApplicationID, "username": "mukabi", "discriminator": "0", "bot": true},
+	}
+}
+
+// pathID parses a snowflake path value, returning 0 if it is missing or invalid
+func pathID(r *http.Request, name string) snowflake.ID {
+	id, _ := snowflake.Parse(r.PathValue(name))
+	return id
+}
+
+// remarshal converts v to out via JSON
+func remarshal(v any, out any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response in the format of the API
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"code": 0, "message": message})
+}
@@ -0,0 +1,152 @@
+// Package fakediscord provides a local stand-in for the Discord gateway and REST API.
+// It speaks enough of both to start the bot against it via the gateway_url and rest_url
+// settings, deliver guilds and interactions, and record everything the bot sends back.
+package fakediscord
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/gorilla/websocket"
+)
+
+// apiPrefix is the path of the REST API, matching the default Discord API version of disgo
+const apiPrefix = "/api/v10"
+
+// Guild is a guild delivered to the bot on identify
+type Guild struct {
+	ID   snowflake.ID
+	Name string
+}
+
+// Request is a REST request received from the bot
+type Request struct {
+	Method string
+	Path   string // Path relative to the API prefix, e.g. /applications/1/commands
+	Body   json.RawMessage
+	Time   time.Time
+}
+
+// Server is a fake Discord gateway and REST API backed by an httptest.Server.
+// Configure the bot with GatewayURL, RestURL and Token to connect it to the server.
+type Server struct {
+	*httptest.Server
+
+	// ApplicationID is the ID of the bot application, encoded in Token
+	ApplicationID snowflake.ID
+	// Token is a bot token accepted by disgo, identifying ApplicationID
+	Token string
+
+	upgrader websocket.Upgrader
+
+	mu           sync.Mutex
+	lastID       snowflake.ID
+	guilds       []Guild
+	conns        []*conn
+	ready        chan struct{}
+	commands     map[snowflake.ID][]discord.ApplicationCommand // Registered commands by guild, 0 for global
+	interactions map[string]*Interaction                       // By interaction token
+	requests     []Request
+	messages     []ChannelMessage
+}
+
+// New starts a fake Discord server. Call Close when done.
+func New() *Server {
+	s := &Server{
+		upgrader:     websocket.Upgrader{},
+		ready:        make(chan struct{}),
+		commands:     map[snowflake.ID][]discord.ApplicationCommand{},
+		interactions: map[string]*Interaction{},
+	}
+	s.ApplicationID = s.newID()
+	s.Token = base64.RawStdEncoding.EncodeToString([]byte(s.ApplicationID.String())) + ".fake.token"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gateway", s.handleGateway)
+	mux.Handle(apiPrefix+"/", http.StripPrefix(apiPrefix, s.restHandler()))
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// GatewayURL returns the WebSocket URL of the gateway
+func (s *Server) GatewayURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http") + "/gateway"
+}
+
+// RestURL returns the base URL of the REST API
+func (s *Server) RestURL() string {
+	return s.URL + apiPrefix
+}
+
+// AddGuild adds a guild the bot is a member of. Guilds added before the bot identifies are
+// part of READY, later ones are delivered with GUILD_CREATE immediately.
+func (s *Server) AddGuild(id snowflake.ID, name string) error {
+	s.mu.Lock()
+	guild := Guild{ID: id, Name: name}
+	s.guilds = append(s.guilds, guild)
+	connected := len(s.conns) > 0
+	s.mu.Unlock()
+
+	if !connected {
+		return nil
+	}
+	return s.Dispatch(gateway.EventTypeGuildCreate, guildPayload(guild))
+}
+
+// RemoveGuild removes the bot from a guild, dispatching GUILD_DELETE
+func (s *Server) RemoveGuild(id snowflake.ID) error {
+	s.mu.Lock()
+	s.guilds = slices.DeleteFunc(s.guilds, func(g Guild) bool { return g.ID == id })
+	s.mu.Unlock()
+
+	return s.Dispatch(gateway.EventTypeGuildDelete, map[string]any{"id": id})
+}
+
+// Ready returns a channel that is closed once the bot has identified and received its guilds
+func (s *Server) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Commands returns the application commands registered for a guild, 0 for global commands
+func (s *Server) Commands(guildID snowflake.ID) []discord.ApplicationCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.commands[guildID])
+}
+
+// Requests returns all REST requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// ChannelMessages returns the messages the bot posted to channels
+func (s *Server) ChannelMessages() []ChannelMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.messages)
+}
+
+// newID generates a new unique snowflake
+func (s *Server) newID() snowflake.ID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newIDLocked()
+}
+
+// newIDLocked generates a new unique snowflake, s.mu must be held
+func (s *Server) newIDLocked() snowflake.ID {
+	id := max(snowflake.New(time.Now()), s.lastID+1)
+	s.lastID = id
+	return id
+}
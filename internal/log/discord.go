@@ -0,0 +1,525 @@
+// Package log provides logging configuration and setup for the application
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/disgo/webhook"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const (
+	defaultDiscordBatchInterval = 10 * time.Second
+	defaultDiscordMaxPerMinute  = 5
+	defaultDiscordDedupeWindow  = 10 * time.Minute
+
+	maxEmbedsPerMessage = 10   // Discord limit of embeds per message
+	maxEmbedCharacters  = 6000 // Discord limit of characters across all embeds of a message
+	maxPendingRecords   = 100  // Records kept while rate limited, older ones are dropped
+	maxTitleLength      = 256
+	maxDescriptionLen   = 4000
+	footerSeparator     = " · "
+
+	redacted = "[REDACTED]"
+)
+
+// DiscordConfig defines forwarding of log records to a Discord webhook or channel
+type DiscordConfig struct {
+	WebhookURL    string        `toml:"webhook_url"`    // Webhook to post to, takes precedence over channel_id
+	ChannelID     snowflake.ID  `toml:"channel_id"`     // Channel to post to with the bot token
+	BatchInterval time.Duration `toml:"batch_interval"` // How often collected records are posted
+	MaxPerMinute  int           `toml:"max_per_minute"` // Maximum number of messages posted per minute
+	DedupeWindow  time.Duration `toml:"dedupe_window"`  // Time during which repeated records are only counted
+}
+
+// Enabled reports whether a webhook or channel is configured
+func (c DiscordConfig) Enabled() bool {
+	return c.WebhookURL != "" || c.ChannelID != 0
+}
+
+// String returns a string representation of the configuration, masking the webhook URL
+func (c DiscordConfig) String() string {
+	return fmt.Sprintf("{WebhookURL:%s ChannelID:%s BatchInterval:%s MaxPerMinute:%d DedupeWindow:%s}",
+		strings.Repeat("*", len(c.WebhookURL)),
+		c.ChannelID,
+		c.BatchInterval,
+		c.MaxPerMinute,
+		c.DedupeWindow,
+	)
+}
+
+// secretPatterns match credentials that may show up in log messages or attributes
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(access_key=)[^&\s"]+`),
+	regexp.MustCompile(`(discord(?:app)?\.com/api/(?:v\d+/)?webhooks/\d+/)[\w-]+`),
+	regexp.MustCompile(`(Bot )[\w-]{20,}\.[\w-]{4,}\.[\w-]{20,}`),
+}
+
+// Redactor removes secrets from log output
+type Redactor struct {
+	secrets []string
+}
+
+// NewRedactor creates a redactor removing the given literal secrets and well-known credential patterns
+func NewRedactor(secrets ...string) *Redactor {
+	r := &Redactor{}
+	for _, secret := range secrets {
+		if secret != "" {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+	return r
+}
+
+// Redact returns s with all secrets replaced
+func (r *Redactor) Redact(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+redacted)
+	}
+	return s
+}
+
+// DiscordSender posts embeds to Discord
+type DiscordSender interface {
+	Send(embeds []discord.Embed) error
+	// Close releases the connections of the sender
+	Close()
+}
+
+// DiscordSenderFunc adapts a function to a DiscordSender without resources to release
+type DiscordSenderFunc func(embeds []discord.Embed) error
+
+// Send calls f(embeds)
+func (f DiscordSenderFunc) Send(embeds []discord.Embed) error {
+	return f(embeds)
+}
+
+// Close does nothing
+func (f DiscordSenderFunc) Close() {}
+
+// webhookSender posts embeds with a webhook client
+type webhookSender struct {
+	client webhook.Client
+}
+
+func (s *webhookSender) Send(embeds []discord.Embed) error {
+	_, err := s.client.CreateEmbeds(embeds)
+	return err
+}
+
+func (s *webhookSender) Close() {
+	s.client.Close(context.Background())
+}
+
+// channelSender posts embeds to a channel with a bot token
+type channelSender struct {
+	client    rest.Rest
+	channelID snowflake.ID
+}
+
+func (s *channelSender) Send(embeds []discord.Embed) error {
+	_, err := s.client.CreateMessage(s.channelID, discord.MessageCreate{Embeds: embeds})
+	return err
+}
+
+func (s *channelSender) Close() {
+	s.client.Close(context.Background())
+}
+
+// discordEntry is a collected log record, counting repeated occurrences
+type discordEntry struct {
+	key     string
+	level   slog.Level
+	message string
+	details string
+	time    time.Time
+	count   int
+}
+
+// discordState is shared by all handlers derived via WithAttrs and WithGroup
+type discordState struct {
+	cfg      DiscordConfig
+	send     DiscordSender
+	redactor *Redactor
+
+	mu       sync.Mutex
+	pending  []*discordEntry
+	lastSent map[string]time.Time // Last time a record key was posted
+	sentAt   []time.Time          // Post times within the last minute
+	dropped  int
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// DiscordHandler is a slog.Handler that batches WARN and ERROR records and posts them as embeds to Discord.
+// Repeated records within the dedupe window are counted instead of posted again, posts are
+// rate limited and secrets are redacted.
+type DiscordHandler struct {
+	state  *discordState
+	attrs  []slog.Attr
+	groups []string
+}
+
+// NewDiscordHandler creates a handler posting records via send and starts its batching loop
+func NewDiscordHandler(cfg DiscordConfig, send DiscordSender, redactor *Redactor) *DiscordHandler {
+	if cfg.BatchInterval <= 0 {
+		cfg.BatchInterval = defaultDiscordBatchInterval
+	}
+	if cfg.MaxPerMinute <= 0 {
+		cfg.MaxPerMinute = defaultDiscordMaxPerMinute
+	}
+	if cfg.DedupeWindow <= 0 {
+		cfg.DedupeWindow = defaultDiscordDedupeWindow
+	}
+	if redactor == nil {
+		redactor = NewRedactor()
+	}
+
+	state := &discordState{
+		cfg:      cfg,
+		send:     send,
+		redactor: redactor,
+		lastSent: map[string]time.Time{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go state.run()
+	return &DiscordHandler{state: state}
+}
+
+// NewWebhookSender creates a sender posting to a Discord webhook URL
+func NewWebhookSender(webhookURL string) (DiscordSender, error) {
+	client, err := webhook.NewWithURL(webhookURL, webhook.WithLogger(discardLogger()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log webhook client: %w", err)
+	}
+	return &webhookSender{client: client}, nil
+}
+
+// NewChannelSender creates a sender posting to a Discord channel with the given bot token
+func NewChannelSender(token string, channelID snowflake.ID) DiscordSender {
+	return &channelSender{
+		client:    rest.New(rest.NewClient(token, rest.WithLogger(discardLogger()))),
+		channelID: channelID,
+	}
+}
+
+// discardLogger returns a logger for the Discord clients, which must not log back into the handler
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func (h *DiscordHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelWarn
+}
+
+func (h *DiscordHandler) Handle(_ context.Context, r slog.Record) error {
+	var details strings.Builder
+	prefix := strings.Join(h.groups, ".")
+	for _, attr := range h.attrs {
+		writeAttr(&details, "", attr)
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&details, prefix, attr)
+		return true
+	})
+
+	message := h.state.redactor.Redact(r.Message)
+	h.state.add(&discordEntry{
+		key:     r.Level.String() + "|" + message,
+		level:   r.Level,
+		message: message,
+		details: h.state.redactor.Redact(details.String()),
+		time:    r.Time,
+		count:   1,
+	})
+	return nil
+}
+
+func (h *DiscordHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefix := strings.Join(h.groups, ".")
+	prefixed := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	prefixed = append(prefixed, h.attrs...)
+	for _, attr := range attrs {
+		if prefix != "" {
+			attr.Key = prefix + "." + attr.Key
+		}
+		prefixed = append(prefixed, attr)
+	}
+	return &DiscordHandler{state: h.state, attrs: prefixed, groups: h.groups}
+}
+
+func (h *DiscordHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &DiscordHandler{state: h.state, attrs: h.attrs, groups: append(h.groups[:len(h.groups):len(h.groups)], name)}
+}
+
+// Close posts remaining records, stops the batching loop and closes the sender
+func (h *DiscordHandler) Close() error {
+	h.state.closeOnce.Do(func() {
+		close(h.state.stop)
+		<-h.state.done
+		h.state.send.Close()
+	})
+	return nil
+}
+
+// writeAttr writes an attribute as a key=value line, flattening groups
+func writeAttr(b *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	key := attr.Key
+	if prefix != "" {
+		key = prefix + "." + key
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, a := range attr.Value.Group() {
+			writeAttr(b, key, a)
+		}
+		return
+	}
+	fmt.Fprintf(b, "%s=%s\n", key, attr.Value)
+}
+
+// add queues an entry, merging it with a pending entry for the same record
+func (s *discordState) add(entry *discordEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pending := range s.pending {
+		if pending.key == entry.key {
+			pending.count++
+			return
+		}
+	}
+
+	if len(s.pending) >= maxPendingRecords {
+		s.pending = s.pending[1:]
+		s.dropped++
+	}
+	s.pending = append(s.pending, entry)
+}
+
+// run posts pending entries every batch interval until stopped
+func (s *discordState) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.BatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			s.flush(true)
+			return
+		case <-ticker.C:
+			s.flush(false)
+		}
+	}
+}
+
+// flush posts pending entries that are not deduplicated, respecting the rate limit.
+// When final is set the rate limit is ignored so nothing is lost on shutdown.
+func (s *discordState) flush(final bool) {
+	now := time.Now()
+
+	s.mu.Lock()
+	var (
+		batch   []*discordEntry
+		dropped int
+	)
+	for _, entry := range s.pending {
+		if sent, ok := s.lastSent[entry.key]; ok && now.Sub(sent) < s.cfg.DedupeWindow && !final {
+			continue // Keep counting until the dedupe window has passed
+		}
+		batch = append(batch, entry)
+	}
+	if len(batch) == 0 {
+		s.mu.Unlock()
+		return
+	}
+
+	// Drop post times older than a minute
+	recent := s.sentAt[:0]
+	for _, t := range s.sentAt {
+		if now.Sub(t) < time.Minute {
+			recent = append(recent, t)
+		}
+	}
+	s.sentAt = recent
+	if len(s.sentAt) >= s.cfg.MaxPerMinute && !final {
+		s.mu.Unlock()
+		return
+	}
+
+	dropped, s.dropped = s.dropped, 0
+	var droppedNote string
+	budget := maxEmbedCharacters
+	if dropped > 0 {
+		droppedNote = fmt.Sprintf("%d older log records were dropped", dropped)
+		budget -= utf8.RuneCountInString(footerSeparator + droppedNote)
+	}
+
+	// Fill the message up to the embed count and character limits, the rest is posted later
+	var (
+		embeds     []discord.Embed
+		characters int
+	)
+	for i, entry := range batch {
+		embed := entry.embed()
+		if len(embeds) == maxEmbedsPerMessage || characters+embedLength(embed) > budget {
+			batch = batch[:i]
+			break
+		}
+		embeds = append(embeds, embed)
+		characters += embedLength(embed)
+	}
+	if droppedNote != "" {
+		// Keep the repeat count of the last record next to the dropped count
+		last := &embeds[len(embeds)-1]
+		if last.Footer != nil {
+			last.Footer = &discord.EmbedFooter{Text: last.Footer.Text + footerSeparator + droppedNote}
+		} else {
+			last.Footer = &discord.EmbedFooter{Text: droppedNote}
+		}
+	}
+
+	s.removePending(batch)
+	for _, entry := range batch {
+		s.lastSent[entry.key] = now
+	}
+	for key, sent := range s.lastSent {
+		if now.Sub(sent) >= s.cfg.DedupeWindow {
+			delete(s.lastSent, key)
+		}
+	}
+	s.sentAt = append(s.sentAt, now)
+	s.mu.Unlock()
+
+	// Errors are written to stderr, logging them would feed them back into this handler
+	if err := s.send.Send(embeds); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to post logs to discord: %s\n", s.redactor.Redact(err.Error()))
+		if final {
+			return // Retrying on shutdown could block forever
+		}
+		s.requeue(batch, dropped)
+		return
+	}
+
+	if final {
+		s.flush(true)
+	}
+}
+
+// requeue puts entries that failed to post back at the front of the pending queue, merging
+// them with records of the same key collected since. Entries beyond the queue limit are dropped.
+func (s *discordState) requeue(entries []*discordEntry, dropped int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dropped += dropped
+	requeued := make([]*discordEntry, 0, len(entries)+len(s.pending))
+	for _, entry := range entries {
+		delete(s.lastSent, entry.key)
+		requeued = append(requeued, entry)
+	}
+	for _, pending := range s.pending {
+		merged := false
+		for _, entry := range entries {
+			if entry.key == pending.key {
+				entry.count += pending.count
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			requeued = append(requeued, pending)
+		}
+	}
+
+	// Keep the newest records, matching add
+	if excess := len(requeued) - maxPendingRecords; excess > 0 {
+		requeued = requeued[excess:]
+		s.dropped += excess
+	}
+	s.pending = requeued
+}
+
+// embedLength returns the number of characters Discord counts towards the per-message limit
+func embedLength(embed discord.Embed) int {
+	n := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+	if embed.Footer != nil {
+		n += utf8.RuneCountInString(embed.Footer.Text)
+	}
+	if embed.Author != nil {
+		n += utf8.RuneCountInString(embed.Author.Name)
+	}
+	for _, field := range embed.Fields {
+		n += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+	}
+	return n
+}
+
+// removePending removes the given entries from the pending queue
+func (s *discordState) removePending(entries []*discordEntry) {
+	remaining := s.pending[:0]
+	for _, pending := range s.pending {
+		sent := false
+		for _, entry := range entries {
+			if pending == entry {
+				sent = true
+				break
+			}
+		}
+		if !sent {
+			remaining = append(remaining, pending)
+		}
+	}
+	s.pending = remaining
+}
+
+// embed renders the entry as a Discord embed
+func (e *discordEntry) embed() discord.Embed {
+	color := 0xFEE75C // Yellow
+	if e.level >= slog.LevelError {
+		color = 0xED4245 // Red
+	}
+
+	embed := discord.Embed{
+		Title:     truncate(e.level.String()+": "+e.message, maxTitleLength),
+		Color:     color,
+		Timestamp: &e.time,
+	}
+	if e.details != "" {
+		embed.Description = "```\n" + truncate(e.details, maxDescriptionLen) + "```"
+	}
+	if e.count > 1 {
+		embed.Footer = &discord.EmbedFooter{Text: fmt.Sprintf("Repeated %d times", e.count)}
+	}
+	return embed
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n-len("…")], "") + "…"
+}
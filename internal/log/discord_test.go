@@ -0,0 +1,266 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/disgo/webhook"
+)
+
+// webhookServer records the embeds posted to a fake Discord webhook
+type webhookServer struct {
+	*httptest.Server
+	status atomic.Int32 // Status returned for posts, 200 if zero
+
+	mu    sync.Mutex
+	posts [][]discord.Embed // Embeds of every post, including failed ones
+}
+
+func newWebhookServer(t *testing.T) *webhookServer {
+	t.Helper()
+	s := &webhookServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Embeds []discord.Embed `json:"embeds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.posts = append(s.posts, body.Embeds)
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if status := int(s.status.Load()); status != 0 {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"message": "unavailable", "code": 0}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "1", "channel_id": "1"}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Posts returns the embeds of every post received so far
+func (s *webhookServer) Posts() [][]discord.Embed {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]discord.Embed(nil), s.posts...)
+}
+
+// newTestDiscordHandler creates a handler posting to the server. Batches are only posted by
+// calling flush, as the batch interval never elapses during a test.
+func newTestDiscordHandler(t *testing.T, s *webhookServer, cfg DiscordConfig) (*DiscordHandler, *slog.Logger) {
+	t.Helper()
+	cfg.BatchInterval = time.Hour
+	client := webhook.New(1, "webhook-token",
+		webhook.WithLogger(discardLogger()),
+		webhook.WithRestClientConfigOpts(rest.WithURL(s.URL), rest.WithLogger(discardLogger())),
+	)
+	h := NewDiscordHandler(cfg, &webhookSender{client: client}, NewRedactor("secret-token"))
+	t.Cleanup(func() { h.Close() })
+	return h, slog.New(h)
+}
+
+// titles returns the embed titles of a post
+func titles(embeds []discord.Embed) []string {
+	var titles []string
+	for _, embed := range embeds {
+		titles = append(titles, embed.Title)
+	}
+	return titles
+}
+
+// footer returns the footer text of an embed
+func footer(embed discord.Embed) string {
+	if embed.Footer == nil {
+		return ""
+	}
+	return embed.Footer.Text
+}
+
+func TestDiscordHandlerDedupe(t *testing.T) {
+	s := newWebhookServer(t)
+	h, logger := newTestDiscordHandler(t, s, DiscordConfig{DedupeWindow: time.Hour})
+
+	for range 3 {
+		logger.Error("boom")
+	}
+	logger.Warn("login with secret-token failed")
+	logger.Info("ignored")
+	h.state.flush(false)
+
+	posts := s.Posts()
+	if len(posts) != 1 || len(posts[0]) != 2 {
+		t.Fatalf("posts = %v, want one post with two embeds", posts)
+	}
+	if got := footer(posts[0][0]); got != "Repeated 3 times" {
+		t.Errorf("footer = %q, want Repeated 3 times", got)
+	}
+	if title := posts[0][1].Title; title != "WARN: login with [REDACTED] failed" {
+		t.Errorf("title = %q, want the token redacted", title)
+	}
+
+	// Repeats within the dedupe window are only counted
+	logger.Error("boom")
+	logger.Error("boom")
+	h.state.flush(false)
+	if n := len(s.Posts()); n != 1 {
+		t.Fatalf("posted %d times within the dedupe window, want 1", n)
+	}
+
+	// Closing posts what was counted
+	h.Close()
+	posts = s.Posts()
+	if len(posts) != 2 || len(posts[1]) != 1 || footer(posts[1][0]) != "Repeated 2 times" {
+		t.Errorf("posts after Close() = %v, want the repeated record", posts)
+	}
+}
+
+func TestDiscordHandlerRateLimit(t *testing.T) {
+	s := newWebhookServer(t)
+	h, logger := newTestDiscordHandler(t, s, DiscordConfig{MaxPerMinute: 1})
+
+	logger.Error("first")
+	h.state.flush(false)
+	logger.Error("second")
+	h.state.flush(false)
+	if n := len(s.Posts()); n != 1 {
+		t.Fatalf("posted %d times with a limit of one per minute, want 1", n)
+	}
+
+	// The final flush ignores the rate limit so nothing is lost on shutdown
+	h.Close()
+	posts := s.Posts()
+	if len(posts) != 2 || titles(posts[1])[0] != "ERROR: second" {
+		t.Errorf("posts after Close() = %v, want the rate limited record", posts)
+	}
+}
+
+func TestDiscordHandlerRequeue(t *testing.T) {
+	s := newWebhookServer(t)
+	h, logger := newTestDiscordHandler(t, s, DiscordConfig{DedupeWindow: time.Hour})
+	_ = captureStderr(t)
+
+	s.status.Store(http.StatusInternalServerError)
+	logger.Error("boom")
+	h.state.flush(false)
+	if n := len(s.Posts()); n != 1 {
+		t.Fatalf("posted %d times, want 1 failed post", n)
+	}
+
+	// The failed record is retried despite the dedupe window and merged with new repeats
+	logger.Error("boom")
+	s.status.Store(0)
+	h.state.flush(false)
+
+	posts := s.Posts()
+	if len(posts) != 2 || len(posts[1]) != 1 {
+		t.Fatalf("posts = %v, want the failed record posted again", posts)
+	}
+	if got := footer(posts[1][0]); got != "Repeated 2 times" {
+		t.Errorf("footer = %q, want Repeated 2 times", got)
+	}
+}
+
+func TestDiscordHandlerFinalFlushDoesNotRetry(t *testing.T) {
+	s := newWebhookServer(t)
+	h, logger := newTestDiscordHandler(t, s, DiscordConfig{})
+	_ = captureStderr(t)
+
+	s.status.Store(http.StatusInternalServerError)
+	logger.Error("boom")
+
+	done := make(chan struct{})
+	go func() {
+		h.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() did not return after a failed post")
+	}
+	if n := len(s.Posts()); n != 1 {
+		t.Errorf("posted %d times on Close(), want 1", n)
+	}
+}
+
+func TestDiscordHandlerDroppedFooter(t *testing.T) {
+	s := newWebhookServer(t)
+	h, logger := newTestDiscordHandler(t, s, DiscordConfig{})
+
+	// One record more than the queue holds drops the oldest
+	for i := range maxPendingRecords + 1 {
+		logger.Error(fmt.Sprintf("record %d", i))
+	}
+	logger.Error("record 10")
+	h.state.flush(false)
+
+	posts := s.Posts()
+	if len(posts) != 1 || len(posts[0]) != maxEmbedsPerMessage {
+		t.Fatalf("posts = %d, want one post with %d embeds", len(posts), maxEmbedsPerMessage)
+	}
+	last := posts[0][maxEmbedsPerMessage-1]
+	if last.Title != "ERROR: record 10" {
+		t.Errorf("last embed = %q, want record 10", last.Title)
+	}
+	if got, want := footer(last), "Repeated 2 times"+footerSeparator+"1 older log records were dropped"; got != want {
+		t.Errorf("footer = %q, want %q", got, want)
+	}
+}
+
+func TestDiscordHandlerCharacterLimit(t *testing.T) {
+	s := newWebhookServer(t)
+	h, logger := newTestDiscordHandler(t, s, DiscordConfig{})
+
+	details := strings.Repeat("x", 2500)
+	for i := range 3 {
+		logger.Error(fmt.Sprintf("record %d", i), slog.String("details", details))
+	}
+	h.state.flush(false)
+	h.state.flush(false)
+
+	posts := s.Posts()
+	if len(posts) != 2 || len(posts[0]) != 2 || len(posts[1]) != 1 {
+		t.Fatalf("posts = %d, want two embeds and then one", len(posts))
+	}
+	for _, post := range posts {
+		characters := 0
+		for _, embed := range post {
+			characters += embedLength(embed)
+		}
+		if characters > maxEmbedCharacters {
+			t.Errorf("post has %d characters, want at most %d", characters, maxEmbedCharacters)
+		}
+	}
+}
+
+func TestDiscordHandlerAttributes(t *testing.T) {
+	s := newWebhookServer(t)
+	h, logger := newTestDiscordHandler(t, s, DiscordConfig{})
+
+	logger.With("guild", "1").WithGroup("query").Log(context.Background(), slog.LevelError, "failed", "table", "servers")
+	h.state.flush(false)
+
+	posts := s.Posts()
+	if len(posts) != 1 {
+		t.Fatalf("posts = %v, want one post", posts)
+	}
+	if want := "```\nguild=1\nquery.table=servers\n```"; posts[0][0].Description != want {
+		t.Errorf("description = %q, want %q", posts[0][0].Description, want)
+	}
+}
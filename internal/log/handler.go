@@ -0,0 +1,103 @@
+// Package log provides logging configuration and setup for the application
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// LoggerKey is the attribute key naming a logger, used to look up per-logger levels
+const LoggerKey = "logger"
+
+// Named returns the default logger tagged with the given name, so its level can be
+// configured separately in the [log.levels] section.
+// Call it when logging instead of storing the result, so configuration reloads are picked up.
+func Named(name string) *slog.Logger {
+	return slog.Default().With(slog.String(LoggerKey, name))
+}
+
+// levelHandler filters records by the level of the named logger they belong to
+type levelHandler struct {
+	handler slog.Handler
+	level   slog.Level
+	levels  map[string]slog.Level
+}
+
+// newLevelHandler wraps a handler with a default level and per-logger overrides
+func newLevelHandler(handler slog.Handler, level slog.Level, levels map[string]slog.Level) *levelHandler {
+	return &levelHandler{handler: handler, level: level, levels: levels}
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level && h.handler.Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	level := h.level
+	for _, attr := range attrs {
+		if attr.Key != LoggerKey {
+			continue
+		}
+		if override, ok := h.levels[attr.Value.String()]; ok {
+			level = override
+		}
+	}
+	return &levelHandler{handler: h.handler.WithAttrs(attrs), level: level, levels: h.levels}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{handler: h.handler.WithGroup(name), level: h.level, levels: h.levels}
+}
+
+// MultiHandler fans out records to multiple handlers
+type MultiHandler struct {
+	handlers []slog.Handler
+}
+
+// NewMultiHandler creates a handler passing every record to all given handlers
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	return &MultiHandler{handlers: handlers}
+}
+
+func (h *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *MultiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return &MultiHandler{handlers: handlers}
+}
+
+func (h *MultiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return &MultiHandler{handlers: handlers}
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	var buf bytes.Buffer
+	sink := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	logger := slog.New(newLevelHandler(sink, slog.LevelInfo, map[string]slog.Level{
+		"db":       slog.LevelDebug,
+		"raiderio": slog.LevelError,
+	}))
+
+	tests := []struct {
+		name   string
+		logger *slog.Logger
+		level  slog.Level
+		want   bool
+	}{
+		{"default level", logger, slog.LevelInfo, true},
+		{"below default level", logger, slog.LevelDebug, false},
+		{"lowered override", logger.With(LoggerKey, "db"), slog.LevelDebug, true},
+		{"raised override", logger.With(LoggerKey, "raiderio"), slog.LevelWarn, false},
+		{"at raised override", logger.With(LoggerKey, "raiderio"), slog.LevelError, true},
+		{"unknown logger", logger.With(LoggerKey, "events"), slog.LevelDebug, false},
+		{"other attributes", logger.With(LoggerKey, "db").With("guild", "1"), slog.LevelDebug, true},
+		{"group", logger.With(LoggerKey, "db").WithGroup("query"), slog.LevelDebug, true},
+		{"renamed logger", logger.With(LoggerKey, "db").With(LoggerKey, "raiderio"), slog.LevelWarn, false},
+		{"attribute in group", logger.WithGroup("query").With(LoggerKey, "db"), slog.LevelDebug, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.logger.Log(context.Background(), tt.level, "message")
+			if logged := strings.Contains(buf.String(), "message"); logged != tt.want {
+				t.Errorf("logged at %s = %t, want %t", tt.level, logged, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,12 @@
 package log
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/mattn/go-colorable"
 	"github.com/topi314/tint"
@@ -24,59 +28,226 @@ const (
 	ansiMagenta = "\033[35m"
 )
 
+// Supported output destinations
+const (
+	OutputStdout = "stdout"
+	OutputFile   = "file"
+	OutputBoth   = "both"
+)
+
 // Config defines logging configuration options
 type Config struct {
-	Level     slog.Level `toml:"level"`      // Minimum log level to output
-	Format    string     `toml:"format"`     // Output format (json or text)
-	AddSource bool       `toml:"add_source"` // Include source file and line in log output
-	NoColor   bool       `toml:"no_color"`   // Disable colored output
+	Level     slog.Level            `toml:"level"`      // Minimum log level to output
+	Format    string                `toml:"format"`     // Output format (json or text)
+	AddSource bool                  `toml:"add_source"` // Include source file and line in log output
+	NoColor   bool                  `toml:"no_color"`   // Disable colored output
+	Output    string                `toml:"output"`     // Output destination (stdout, file or both)
+	File      FileConfig            `toml:"file"`       // Log file settings, used when output includes a file
+	Levels    map[string]slog.Level `toml:"levels"`     // Minimum log level per named logger, overriding Level
+	Discord   DiscordConfig         `toml:"discord"`    // Forwarding of warnings and errors to Discord
 }
 
-// Setup configures the global logger based on the provided configuration
-func Setup(cfg Config) {
-	var handler slog.Handler
+// Option configures optional Setup dependencies
+type Option func(*options)
 
-	switch cfg.Format {
-	case "json":
-		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			AddSource: cfg.AddSource,
-			Level:     cfg.Level,
-		})
+type options struct {
+	token   string
+	secrets []string
+}
 
-	case "text":
-		handler = tint.NewHandler(colorable.NewColorable(os.Stdout), &tint.Options{
-			AddSource: cfg.AddSource,
-			Level:     cfg.Level,
-			NoColor:   cfg.NoColor,
-			LevelColors: map[slog.Level]string{
-				slog.LevelDebug: ansiMagenta,
-				slog.LevelInfo:  ansiGreen,
-				slog.LevelWarn:  ansiYellow,
-				slog.LevelError: ansiRed,
-			},
-			Colors: map[tint.Kind]string{
-				tint.KindTime:            ansiYellowBold,
-				tint.KindSourceFile:      ansiCyanBold,
-				tint.KindSourceSeparator: ansiCyanBoldFaint,
-				tint.KindSourceLine:      ansiCyanBold,
-				tint.KindMessage:         ansiWhiteBold,
-				tint.KindKey:             ansiFaint,
-				tint.KindSeparator:       ansiFaint,
-				tint.KindValue:           ansiWhiteBold,
-				tint.KindErrorKey:        ansiRedBold,
-			},
-		})
+// WithBotToken sets the bot token used to post logs to a Discord channel.
+// The token is also redacted from forwarded logs.
+func WithBotToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+		o.secrets = append(o.secrets, token)
+	}
+}
+
+// WithSecrets sets values that are redacted from logs forwarded to Discord
+func WithSecrets(secrets ...string) Option {
+	return func(o *options) {
+		o.secrets = append(o.secrets, secrets...)
+	}
+}
+
+// FileConfig defines the log file and its rotation limits
+type FileConfig struct {
+	Path       string        `toml:"path"`        // Path of the active log file
+	MaxSizeMB  int           `toml:"max_size_mb"` // Size in megabytes at which the file is rotated, 0 uses the default
+	MaxAge     time.Duration `toml:"max_age"`     // Age after which rotated files are removed, 0 keeps them
+	MaxBackups int           `toml:"max_backups"` // Number of rotated files to keep, 0 keeps all
+}
 
+// Validate checks the logging configuration for unsupported values
+func (c Config) Validate() error {
+	switch c.Format {
+	case "", "text", "json":
 	default:
-		slog.Error("Unsupported log format, defaulting to text",
-			slog.String("format", cfg.Format))
+		return fmt.Errorf("unknown log format %q, expected text or json", c.Format)
+	}
+
+	switch c.Output {
+	case "", OutputStdout:
+	case OutputFile, OutputBoth:
+		if c.File.Path == "" {
+			return fmt.Errorf("log output %q requires a file path", c.Output)
+		}
+	default:
+		return fmt.Errorf("unknown log output %q, expected stdout, file or both", c.Output)
+	}
+
+	if c.File.MaxSizeMB < 0 || c.File.MaxAge < 0 || c.File.MaxBackups < 0 {
+		return fmt.Errorf("log file rotation limits must not be negative")
+	}
+
+	if c.Discord.BatchInterval < 0 || c.Discord.MaxPerMinute < 0 || c.Discord.DedupeWindow < 0 {
+		return fmt.Errorf("discord log limits must not be negative")
+	}
+
+	for name := range c.Levels {
+		if name == "" {
+			return fmt.Errorf("log level overrides require a logger name")
+		}
+	}
+	return nil
+}
+
+var (
+	mu      sync.Mutex
+	closers []io.Closer // Log files and handlers opened by the current setup
+)
+
+// Setup configures the global logger based on the provided configuration.
+// It may be called again to apply a changed configuration, closing the previous log file
+// and flushing logs pending for Discord.
+func Setup(cfg Config, opts ...Option) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// Sinks log everything enabled for any logger, the level handler filters per logger
+	sinkLevel := cfg.Level
+	for _, level := range cfg.Levels {
+		sinkLevel = min(sinkLevel, level)
+	}
+
+	var (
+		handlers []slog.Handler
+		file     *RotatingFile
+	)
+	if cfg.Output == "" || cfg.Output == OutputStdout || cfg.Output == OutputBoth {
+		handlers = append(handlers, newHandler(cfg, colorable.NewColorable(os.Stdout), sinkLevel, cfg.NoColor))
+	}
+	if cfg.Output == OutputFile || cfg.Output == OutputBoth {
+		var err error
+		file, err = OpenRotatingFile(cfg.File)
+		if err != nil {
+			return err
+		}
+		handlers = append(handlers, newHandler(cfg, file, sinkLevel, true))
+	}
+
+	var discordHandler *DiscordHandler
+	if cfg.Discord.Enabled() {
+		send, err := newDiscordSender(cfg.Discord, o.token)
+		if err != nil {
+			if file != nil {
+				_ = file.Close()
+			}
+			return err
+		}
+		discordHandler = NewDiscordHandler(cfg.Discord, send, NewRedactor(o.secrets...))
+		handlers = append(handlers, discordHandler)
+	}
+
+	var handler slog.Handler = handlers[0]
+	if len(handlers) > 1 {
+		handler = NewMultiHandler(handlers...)
+	}
+	slog.SetDefault(slog.New(newLevelHandler(handler, cfg.Level, cfg.Levels)))
+
+	var current []io.Closer
+	if discordHandler != nil {
+		current = append(current, discordHandler)
+	}
+	if file != nil {
+		current = append(current, file)
+	}
+
+	mu.Lock()
+	previous := closers
+	closers = current
+	mu.Unlock()
+
+	closeAll(previous)
+	return nil
+}
+
+// Close flushes pending Discord logs and closes the log file, call it before exiting
+func Close() {
+	mu.Lock()
+	previous := closers
+	closers = nil
+	mu.Unlock()
+
+	closeAll(previous)
+}
+
+// closeAll closes the given handlers and files, logging failures
+func closeAll(cs []io.Closer) {
+	for _, c := range cs {
+		if err := c.Close(); err != nil {
+			slog.Error("Failed to close log output", tint.Err(err))
+		}
+	}
+}
+
+// newDiscordSender creates the sender for the configured webhook or channel
+func newDiscordSender(cfg DiscordConfig, token string) (DiscordSender, error) {
+	if cfg.WebhookURL != "" {
+		return NewWebhookSender(cfg.WebhookURL)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("posting logs to a discord channel requires a bot token")
+	}
+	return NewChannelSender(token, cfg.ChannelID), nil
+}
 
-		// Set up default text handler without colors
-		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+// newHandler creates a handler writing records in the configured format
+func newHandler(cfg Config, w io.Writer, level slog.Level, noColor bool) slog.Handler {
+	if cfg.Format == "json" {
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{
 			AddSource: cfg.AddSource,
-			Level:     cfg.Level,
+			Level:     level,
 		})
 	}
 
-	slog.SetDefault(slog.New(handler))
+	return tint.NewHandler(w, &tint.Options{
+		AddSource: cfg.AddSource,
+		Level:     level,
+		NoColor:   noColor,
+		LevelColors: map[slog.Level]string{
+			slog.LevelDebug: ansiMagenta,
+			slog.LevelInfo:  ansiGreen,
+			slog.LevelWarn:  ansiYellow,
+			slog.LevelError: ansiRed,
+		},
+		Colors: map[tint.Kind]string{
+			tint.KindTime:            ansiYellowBold,
+			tint.KindSourceFile:      ansiCyanBold,
+			tint.KindSourceSeparator: ansiCyanBoldFaint,
+			tint.KindSourceLine:      ansiCyanBold,
+			tint.KindMessage:         ansiWhiteBold,
+			tint.KindKey:             ansiFaint,
+			tint.KindSeparator:       ansiFaint,
+			tint.KindValue:           ansiWhiteBold,
+			tint.KindErrorKey:        ansiRedBold,
+		},
+	})
 }
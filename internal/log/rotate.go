@@ -0,0 +1,217 @@
+// Package log provides logging configuration and setup for the application
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxSizeMB = 100
+	backupTimeFormat = "20060102T150405.000"
+)
+
+// RotatingFile is an io.WriteCloser appending to a log file that is rotated once it
+// exceeds its maximum size. Rotated files are renamed with a timestamp and removed
+// once they exceed the maximum age or backup count.
+type RotatingFile struct {
+	cfg     FileConfig
+	maxSize int64
+	now     func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	rotateAt int64 // Size at which the file is rotated, raised after a failed rotation
+	closed   bool
+}
+
+// OpenRotatingFile opens or creates the configured log file for appending
+func OpenRotatingFile(cfg FileConfig) (*RotatingFile, error) {
+	maxSizeMB := cfg.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultMaxSizeMB
+	}
+
+	f := &RotatingFile{
+		cfg:     cfg,
+		maxSize: int64(maxSizeMB) * 1024 * 1024,
+		now:     time.Now,
+	}
+	f.rotateAt = f.maxSize
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p to the log file, rotating it first if p would exceed the maximum size
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	// Retry opening the log file if a previous rotation failed to reopen it
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.size > 0 && f.size+int64(len(p)) > f.rotateAt {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the log file for appending and records its current size
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate renames the current log file to a timestamped backup and opens a new one.
+// If the rename fails, logging continues in the original file and rotation is retried
+// once the file has grown by another maximum size.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	f.file = nil
+
+	renameErr := os.Rename(f.cfg.Path, f.backupName(f.now()))
+	if renameErr != nil {
+		// Back off instead of failing again on every write
+		f.rotateAt = f.size + f.maxSize
+	} else {
+		f.rotateAt = f.maxSize
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		// Logging to stderr instead of the default logger, which may write to this file
+		fmt.Fprintf(os.Stderr, "failed to rotate log file: %v\n", renameErr)
+		return nil
+	}
+
+	// Pruning failures must not stop logging. The default logger may write to this file,
+	// so report them on stderr instead of logging while holding the lock.
+	if err := f.prune(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to remove old log files: %v\n", err)
+	}
+	return nil
+}
+
+// backupName returns the name of a backup rotated at the given time, e.g. mukabi-20250101T120000.000.log
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.cfg.Path)
+	return strings.TrimSuffix(f.cfg.Path, ext) + "-" + t.Format(backupTimeFormat) + ext
+}
+
+// backup is a rotated log file
+type backup struct {
+	path      string
+	rotatedAt time.Time
+}
+
+// backups returns the rotated log files, newest first. Other files sharing the name of the
+// log file as prefix, e.g. mukabi-access.log next to mukabi.log, are ignored.
+func (f *RotatingFile) backups() ([]backup, error) {
+	dir := filepath.Dir(f.cfg.Path)
+	ext := filepath.Ext(f.cfg.Path)
+	prefix := strings.TrimSuffix(filepath.Base(f.cfg.Path), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		rotatedAt, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), rotatedAt: rotatedAt})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].rotatedAt.After(backups[j].rotatedAt)
+	})
+	return backups, nil
+}
+
+// prune removes backups exceeding the maximum age or count, oldest first
+func (f *RotatingFile) prune() error {
+	if f.cfg.MaxAge <= 0 && f.cfg.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+
+	cutoff := f.now().Add(-f.cfg.MaxAge)
+	for i, backup := range backups {
+		expired := f.cfg.MaxBackups > 0 && i >= f.cfg.MaxBackups
+		if f.cfg.MaxAge > 0 && backup.rotatedAt.Before(cutoff) {
+			expired = true
+		}
+		if !expired {
+			continue
+		}
+		if err := os.Remove(backup.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
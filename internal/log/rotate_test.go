@@ -0,0 +1,191 @@
+package log
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rotationTime is the clock of rotating files in tests
+var rotationTime = time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+
+// openTestFile opens a rotating file in a temporary directory, rotated once it exceeds 100 bytes
+func openTestFile(t *testing.T, cfg FileConfig) *RotatingFile {
+	t.Helper()
+	if cfg.Path == "" {
+		cfg.Path = filepath.Join(t.TempDir(), "mukabi.log")
+	}
+	f, err := OpenRotatingFile(cfg)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() error = %s", err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	f.maxSize, f.rotateAt = 100, 100
+	f.now = func() time.Time { return rotationTime }
+	return f
+}
+
+// write writes n bytes to the file
+func write(t *testing.T, f *RotatingFile, n int) {
+	t.Helper()
+	if _, err := f.Write([]byte(strings.Repeat("x", n))); err != nil {
+		t.Fatalf("Write() error = %s", err)
+	}
+}
+
+// fileSize returns the size of a file
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat %s: %s", path, err)
+	}
+	return info.Size()
+}
+
+// captureStderr redirects os.Stderr to a file and returns a func reading what was written
+func captureStderr(t *testing.T) func() string {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatalf("failed to create stderr file: %s", err)
+	}
+	stderr := os.Stderr
+	os.Stderr = file
+	t.Cleanup(func() {
+		os.Stderr = stderr
+		file.Close()
+	})
+
+	return func() string {
+		data, err := os.ReadFile(file.Name())
+		if err != nil {
+			t.Fatalf("failed to read stderr: %s", err)
+		}
+		return string(data)
+	}
+}
+
+func TestRotatingFileRotates(t *testing.T) {
+	f := openTestFile(t, FileConfig{})
+
+	write(t, f, 60)
+	write(t, f, 30)
+	if _, err := os.Stat(f.backupName(rotationTime)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("rotated below the maximum size, backup error = %v", err)
+	}
+
+	write(t, f, 20)
+	if size := fileSize(t, f.backupName(rotationTime)); size != 90 {
+		t.Errorf("backup size = %d, want 90", size)
+	}
+	if size := fileSize(t, f.cfg.Path); size != 20 {
+		t.Errorf("log file size = %d, want 20", size)
+	}
+}
+
+func TestRotatingFileBacksOffAfterFailedRotation(t *testing.T) {
+	f := openTestFile(t, FileConfig{})
+	stderr := captureStderr(t)
+
+	// A non-empty directory in place of the backup makes the rename fail
+	blocker := f.backupName(rotationTime)
+	if err := os.MkdirAll(filepath.Join(blocker, "blocker"), 0o755); err != nil {
+		t.Fatalf("failed to create blocking directory: %s", err)
+	}
+
+	write(t, f, 60)
+	write(t, f, 60)
+	if size := fileSize(t, f.cfg.Path); size != 120 {
+		t.Errorf("log file size after failed rotation = %d, want 120", size)
+	}
+
+	// Rotation is not retried before the file has grown by another maximum size
+	for range 4 {
+		write(t, f, 10)
+	}
+	if n := strings.Count(stderr(), "failed to rotate log file"); n != 1 {
+		t.Errorf("reported %d failed rotations, want 1", n)
+	}
+
+	if err := os.RemoveAll(blocker); err != nil {
+		t.Fatalf("failed to remove blocking directory: %s", err)
+	}
+	write(t, f, 10)
+	if size := fileSize(t, blocker); size != 160 {
+		t.Errorf("backup size = %d, want 160", size)
+	}
+	if f.rotateAt != f.maxSize {
+		t.Errorf("rotation threshold = %d after successful rotation, want %d", f.rotateAt, f.maxSize)
+	}
+}
+
+func TestRotatingFileClosed(t *testing.T) {
+	f := openTestFile(t, FileConfig{})
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %s", err)
+	}
+	if _, err := f.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write() after Close() error = %v, want %v", err, os.ErrClosed)
+	}
+}
+
+func TestRotatingFilePrune(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  FileConfig
+		want []string // Remaining backups besides the one just rotated, by age in hours
+	}{
+		{"unlimited", FileConfig{}, []string{"1h", "30h", "50h"}},
+		{"max backups", FileConfig{MaxBackups: 2}, []string{"1h"}},
+		{"max age", FileConfig{MaxAge: 48 * time.Hour}, []string{"1h", "30h"}},
+		{"both", FileConfig{MaxBackups: 3, MaxAge: 24 * time.Hour}, []string{"1h"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.cfg.Path = filepath.Join(dir, "mukabi.log")
+			f := openTestFile(t, tt.cfg)
+
+			backups := map[string]string{}
+			for _, age := range []string{"1h", "30h", "50h"} {
+				d, _ := time.ParseDuration(age)
+				backups[age] = f.backupName(rotationTime.Add(-d))
+			}
+			// Files sharing the prefix that are not backups must survive pruning
+			unrelated := []string{"mukabi-access.log", "mukabi-2026.log", "mukabi-20260101T000000.000.txt", "other.log"}
+			for _, path := range append(slices.Collect(maps.Values(backups)), unrelated...) {
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+					t.Fatalf("failed to create %s: %s", path, err)
+				}
+			}
+
+			write(t, f, 60)
+			write(t, f, 60)
+
+			if _, err := os.Stat(f.backupName(rotationTime)); err != nil {
+				t.Errorf("rotated backup missing: %s", err)
+			}
+			for age, path := range backups {
+				_, err := os.Stat(path)
+				if kept := slices.Contains(tt.want, age); kept != (err == nil) {
+					t.Errorf("backup %s kept = %t, want %t", age, err == nil, kept)
+				}
+			}
+			for _, name := range unrelated {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("unrelated file %s was removed", name)
+				}
+			}
+		})
+	}
+}
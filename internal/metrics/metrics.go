@@ -0,0 +1,89 @@
+// Package metrics provides Prometheus collectors for the application
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "mukabi"
+
+var (
+	interactions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "interactions_total",
+		Help:      "Number of handled Discord interactions by command, interaction type and outcome.",
+	}, []string{"command", "type", "outcome"})
+
+	interactionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "interaction_duration_seconds",
+		Help:      "Latency of Discord interaction handlers by command and interaction type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command", "type"})
+
+	raiderIORequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "raiderio_requests_total",
+		Help:      "Number of Raider.IO API requests by endpoint and status code.",
+	}, []string{"endpoint", "status"})
+
+	raiderIOCache = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "raiderio_cache_lookups_total",
+		Help:      "Number of Raider.IO cache lookups by endpoint and result.",
+	}, []string{"endpoint", "result"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of database queries by query name.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"query"})
+)
+
+// Handler returns the HTTP handler serving metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveInteraction records a handled interaction and its latency
+func ObserveInteraction(command, interactionType string, start time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	interactions.WithLabelValues(command, interactionType, outcome).Inc()
+	interactionDuration.WithLabelValues(command, interactionType).Observe(time.Since(start).Seconds())
+}
+
+// ObserveRaiderIORequest records a Raider.IO API request. A status code of 0 means the request failed.
+func ObserveRaiderIORequest(endpoint string, statusCode int) {
+	status := "error"
+	if statusCode > 0 {
+		status = strconv.Itoa(statusCode)
+	}
+	raiderIORequests.WithLabelValues(endpoint, status).Inc()
+}
+
+// ObserveRaiderIOCache records a Raider.IO cache lookup
+func ObserveRaiderIOCache(endpoint string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	raiderIOCache.WithLabelValues(endpoint, result).Inc()
+}
+
+// ObserveQuery returns a function recording the latency of a database query when called
+func ObserveQuery(query string) func() {
+	start := time.Now()
+	return func() {
+		dbQueryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+	}
+}
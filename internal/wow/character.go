@@ -0,0 +1,10 @@
+// Package wow provides World of Warcraft domain models shared across the bot
+package wow
+
+import "strings"
+
+// CharacterKey returns the case-insensitive identity of a character name,
+// so "Zoki" and "zoki" refer to the same character
+func CharacterKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,41 @@
+// Package wow provides World of Warcraft domain models shared across the bot
+package wow
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// FoldRealmName lowercases a realm name, strips diacritics and apostrophes and separates words with
+// single spaces, so "Aggra (Português)", "aggra-portugues" and "Aggra Portugues" all fold to
+// "aggra portugues".
+func FoldRealmName(name string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, name)
+	if err != nil {
+		folded = name
+	}
+
+	folded = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\'' || r == '’':
+			return -1
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		default:
+			return ' '
+		}
+	}, folded)
+
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// RealmSlug converts a realm name to its Raider.IO slug, e.g. "Twisting Nether" to "twisting-nether"
+// and "Aggra (Português)" to "aggra-portugues". Slugs are returned unchanged.
+func RealmSlug(realm string) string {
+	return strings.ReplaceAll(FoldRealmName(realm), " ", "-")
+}
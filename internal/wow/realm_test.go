@@ -0,0 +1,32 @@
+package wow_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zokiio/mukabi/internal/wow"
+)
+
+func TestFoldRealmName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Twisting Nether", "twisting nether"},
+		{"twisting-nether", "twisting nether"},
+		{"Aggra (Português)", "aggra portugues"},
+		{"  Aggra   Portugues ", "aggra portugues"},
+		{"Kael'thas", "kaelthas"},
+		{"Kel’Thuzad", "kelthuzad"},
+		{"Гордунни", "гордунни"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := wow.FoldRealmName(tt.name); got != tt.want {
+			t.Errorf("FoldRealmName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if got, want := wow.RealmSlug(tt.name), strings.ReplaceAll(tt.want, " ", "-"); got != want {
+			t.Errorf("RealmSlug(%q) = %q, want %q", tt.name, got, want)
+		}
+	}
+}
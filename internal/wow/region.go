@@ -0,0 +1,60 @@
+// Package wow provides World of Warcraft domain models shared across the bot
+package wow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrUnknownRegion is returned when a region code is not supported
+var ErrUnknownRegion = errors.New("unknown region")
+
+// Region describes a World of Warcraft game region supported by Raider.IO
+type Region struct {
+	Code      string       // Raider.IO region code, e.g. "eu"
+	Name      string       // Display name
+	Locale    string       // Default game locale of the region
+	ResetDay  time.Weekday // Day of the weekly reset in UTC
+	ResetHour int          // Hour of the weekly reset in UTC
+}
+
+// regions lists all supported regions in display order
+var regions = []Region{
+	{Code: "us", Name: "Americas & Oceania", Locale: "en_US", ResetDay: time.Tuesday, ResetHour: 15},
+	{Code: "eu", Name: "Europe", Locale: "en_GB", ResetDay: time.Wednesday, ResetHour: 4},
+	{Code: "kr", Name: "Korea", Locale: "ko_KR", ResetDay: time.Wednesday, ResetHour: 23},
+	{Code: "tw", Name: "Taiwan", Locale: "zh_TW", ResetDay: time.Wednesday, ResetHour: 23},
+	{Code: "cn", Name: "China", Locale: "zh_CN", ResetDay: time.Wednesday, ResetHour: 23},
+}
+
+// Regions returns all supported regions
+func Regions() []Region {
+	return append([]Region(nil), regions...)
+}
+
+// LookupRegion returns the region for a code, ignoring case
+func LookupRegion(code string) (Region, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	for _, region := range regions {
+		if region.Code == code {
+			return region, true
+		}
+	}
+	return Region{}, false
+}
+
+// ParseRegion returns the region for a code or ErrUnknownRegion
+func ParseRegion(code string) (Region, error) {
+	region, ok := LookupRegion(code)
+	if !ok {
+		return Region{}, fmt.Errorf("%w: %q", ErrUnknownRegion, code)
+	}
+	return region, nil
+}
+
+// String returns the region code
+func (r Region) String() string {
+	return r.Code
+}
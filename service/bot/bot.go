@@ -6,46 +6,87 @@ import (
 	_ "embed"
 	"fmt"
 	"log/slog"
+	"net/http"
 
 	"github.com/disgoorg/disgo"
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/cache"
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/gateway"
-	"github.com/disgoorg/disgo/handler"
 	"github.com/disgoorg/disgo/rest"
 	"github.com/disgoorg/disgo/sharding"
 	"github.com/topi314/tint"
 
 	"github.com/zokiio/mukabi/external"
+	"github.com/zokiio/mukabi/external/raiderio"
 	"github.com/zokiio/mukabi/service/bot/db"
 )
 
 //go:embed sql/schema.sql
 var schema string
 
+//go:embed sql/schema_sqlite.sql
+var sqliteSchema string
+
 // Bot represents the main bot instance with all its dependencies
 type Bot struct {
 	Config   Config
 	Version  string
 	Commit   string
 	Discord  bot.Client
-	Database *db.Database
+	Database db.Store
 	External *external.Services
+
+	ctx        context.Context
+	cancel     context.CancelFunc
+	tasks      tasks
+	stopJobs   context.CancelFunc
+	httpServer *http.Server
+	reloader   reloader
+}
+
+// Option configures optional dependencies of a bot
+type Option func(*Bot)
+
+// WithStore uses the given store instead of opening the configured database
+func WithStore(store db.Store) Option {
+	return func(b *Bot) {
+		b.Database = store
+	}
+}
+
+// WithExternal uses the given external services instead of the configured clients
+func WithExternal(services *external.Services) Option {
+	return func(b *Bot) {
+		b.External = services
+	}
 }
 
 // New creates a new bot instance with the provided configuration
-func New(cfg Config, version, commit string) (*Bot, error) {
+func New(cfg Config, version, commit string, opts ...Option) (*Bot, error) {
 	b := &Bot{
-		Config:   cfg,
-		Version:  version,
-		Commit:   commit,
-		External: external.NewServices(cfg.External.RaiderIOKey),
+		Config:  cfg,
+		Version: version,
+		Commit:  commit,
+	}
+	for _, opt := range opts {
+		opt(b)
 	}
 
+	if b.External == nil {
+		raiderIO := raiderio.New(cfg.External.RaiderIOKey)
+		raiderIO.SetCacheTTL(cfg.External.raiderIOCacheTTL())
+		b.External = external.New(external.WithRaiderIO(raiderIO))
+	}
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+
 	// Configure gateway options
+	intents := []gateway.Intents{gateway.IntentGuilds, gateway.IntentGuildVoiceStates}
+	if cfg.Retention.TrackMembers {
+		intents = append(intents, gateway.IntentGuildMembers)
+	}
 	gatewayOpts := []gateway.ConfigOpt{
-		gateway.WithIntents(gateway.IntentGuilds, gateway.IntentGuildVoiceStates),
+		gateway.WithIntents(intents...),
 	}
 
 	// Configure shard manager
@@ -64,19 +105,10 @@ func New(cfg Config, version, commit string) (*Bot, error) {
 		}
 	}
 
-	// Configure REST client
-	restOpts := []rest.ConfigOpt{}
-	if cfg.Bot.RestURL != "" {
-		restOpts = append(restOpts,
-			rest.WithURL(cfg.Bot.RestURL),
-			rest.WithRateLimiter(rest.NewNoopRateLimiter()),
-		)
-	}
-
 	// Create Discord client
 	client, err := disgo.New(cfg.Bot.Token,
 		bot.WithShardManagerConfigOpts(shardOpts...),
-		bot.WithRestClientConfigOpts(restOpts...),
+		bot.WithRestClientConfigOpts(restConfigOpts(cfg)...),
 		bot.WithCacheConfigOpts(
 			cache.WithCaches(cache.FlagGuilds, cache.FlagVoiceStates),
 		),
@@ -86,32 +118,90 @@ func New(cfg Config, version, commit string) (*Bot, error) {
 	}
 
 	// Initialize database
-	database, err := db.New(cfg.Database.Driver, cfg.Database, schema)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create database: %w", err)
+	if b.Database == nil {
+		database, err := OpenDatabase(cfg)
+		if err != nil {
+			return nil, err
+		}
+		b.Database = database
 	}
 
 	b.Discord = client
-	b.Database = database
 	return b, nil
 }
 
+// restConfigOpts returns the REST client options, pointing it at a custom REST URL if configured
+func restConfigOpts(cfg Config) []rest.ConfigOpt {
+	if cfg.Bot.RestURL == "" {
+		return nil
+	}
+	return []rest.ConfigOpt{
+		rest.WithURL(cfg.Bot.RestURL),
+		rest.WithRateLimiter(rest.NewNoopRateLimiter()),
+	}
+}
+
+// OpenDatabase connects to the configured database and applies the schema of its driver
+func OpenDatabase(cfg Config) (*db.Database, error) {
+	driverSchema := schema
+	if cfg.Database.Driver == db.DriverSQLite {
+		driverSchema = sqliteSchema
+	}
+
+	database, err := db.New(cfg.Database.Driver, cfg.Database, driverSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	}
+	return database, nil
+}
+
 // Start initializes the bot and starts listening for Discord events
 func (b *Bot) Start(commands []discord.ApplicationCommandCreate) error {
+	if b.Config.HTTP.Enabled {
+		b.startHTTP()
+	}
+
 	if b.Config.Bot.SyncCommands {
-		slog.Info("Syncing slash commands...")
+		slog.Info("Syncing slash commands...",
+			slog.Bool("dev_mode", b.Config.Bot.DevMode),
+			slog.Any("guild_ids", b.Config.Bot.GuildIDs),
+		)
 
-		if err := handler.SyncCommands(b.Discord, commands, b.Config.Bot.GuildIDs); err != nil {
+		if err := b.SyncCommands(commands); err != nil {
 			return fmt.Errorf("failed to sync commands: %w", err)
 		}
 	}
 
-	return b.Discord.OpenShardManager(context.Background())
+	if err := b.Discord.OpenShardManager(b.ctx); err != nil {
+		return err
+	}
+
+	// Start background jobs
+	jobsCtx, cancel := context.WithCancel(b.ctx)
+	b.stopJobs = cancel
+	b.Go(func() { b.runRetention(jobsCtx) })
+
+	return nil
 }
 
-// Close gracefully shuts down the bot
+// Close gracefully shuts down the bot.
+// The gateway is closed first so no new interactions arrive, then in-flight handlers and
+// background jobs are drained, and finally the REST client and database are closed.
 func (b *Bot) Close() {
-	b.Discord.Close(context.Background())
+	ctx := context.Background()
+
+	// Stop receiving events and signal background jobs to stop
+	b.Discord.ShardManager().Close(ctx)
+	if b.stopJobs != nil {
+		b.stopJobs()
+	}
+	b.stopHTTP(ctx)
+
+	// Let in-flight work finish before cancelling the root context
+	b.drain()
+	b.cancel()
+
+	b.Discord.Close(ctx)
 	if err := b.Database.Close(); err != nil {
 		slog.Error("Error closing database connection", tint.Err(err))
 	}
@@ -0,0 +1,188 @@
+// Package commands implements Discord slash command handlers for the bot
+package commands
+
+import (
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/zokiio/mukabi/service/bot/embeds"
+	"github.com/zokiio/mukabi/service/bot/i18n"
+)
+
+// helpSelectID is the custom ID of the command select menu attached to help messages
+const helpSelectID = "/help/select"
+
+type helpCmd struct{}
+
+func init() {
+	RegisterCommand(&helpCmd{})
+}
+
+func (c *helpCmd) Definition() discord.ApplicationCommandCreate {
+	return i18n.LocalizeCommand(discord.SlashCommandCreate{
+		Name:        "help",
+		Description: "Show available commands and how to use them",
+		Options: []discord.ApplicationCommandOption{
+			&discord.ApplicationCommandOptionString{
+				Name:         "command",
+				Description:  "Command to show details for",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	})
+}
+
+func (c *helpCmd) Handler(cmd *Commander) handler.CommandHandler {
+	return func(e *handler.CommandEvent) error {
+		defs := availableCommands(cmd.Config.Bot.DevMode, e.GuildID(), e.Member())
+
+		name, ok := e.SlashCommandInteractionData().OptString("command")
+		if !ok {
+			return e.CreateMessage(helpMessage(e.Locale(), embeds.HelpOverview(e.Locale(), defs), defs))
+		}
+
+		def, found := findCommand(defs, name)
+		if !found {
+			return e.CreateMessage(embeds.Error(e.Locale(), "errors.unknown_command", name))
+		}
+		return e.CreateMessage(helpMessage(e.Locale(), embeds.HelpCommand(e.Locale(), def), defs))
+	}
+}
+
+func (c *helpCmd) AutocompleteHandler(cmd *Commander) handler.AutocompleteHandler {
+	return func(e *handler.AutocompleteEvent) error {
+		query := strings.ToLower(strings.TrimPrefix(e.Data.String("command"), "/"))
+
+		choices := make([]discord.AutocompleteChoice, 0, 25)
+		for _, def := range availableCommands(cmd.Config.Bot.DevMode, e.GuildID(), e.Member()) {
+			if !strings.Contains(strings.ToLower(def.CommandName()), query) {
+				continue
+			}
+			choices = append(choices, discord.AutocompleteChoiceString{
+				Name:  def.CommandName(),
+				Value: def.CommandName(),
+			})
+
+			if len(choices) >= 25 {
+				break
+			}
+		}
+
+		return e.AutocompleteResult(choices)
+	}
+}
+
+// ComponentHandlers returns the handler for the command select menu
+func (c *helpCmd) ComponentHandlers(cmd *Commander) map[string]handler.ComponentHandler {
+	return map[string]handler.ComponentHandler{
+		helpSelectID: func(e *handler.ComponentEvent) error {
+			values := e.StringSelectMenuInteractionData().Values
+			if len(values) == 0 {
+				return e.DeferUpdateMessage()
+			}
+
+			defs := availableCommands(cmd.Config.Bot.DevMode, e.GuildID(), e.Member())
+			def, found := findCommand(defs, values[0])
+			if !found {
+				return e.CreateMessage(embeds.Error(e.Locale(), "errors.unknown_command", values[0]))
+			}
+
+			msg := helpMessage(e.Locale(), embeds.HelpCommand(e.Locale(), def), defs)
+			return e.UpdateMessage(discord.MessageUpdate{
+				Embeds:     &msg.Embeds,
+				Components: &msg.Components,
+			})
+		},
+	}
+}
+
+// helpMessage builds an ephemeral help message with a select menu to drill into a command
+func helpMessage(locale discord.Locale, embed discord.Embed, defs []discord.ApplicationCommandCreate) discord.MessageCreate {
+	options := make([]discord.StringSelectMenuOption, 0, len(defs))
+	for _, def := range defs {
+		if len(options) >= 25 {
+			break
+		}
+		options = append(options, discord.NewStringSelectMenuOption(def.CommandName(), def.CommandName()))
+	}
+
+	return discord.MessageCreate{
+		Embeds: []discord.Embed{embed},
+		Components: []discord.ContainerComponent{
+			discord.NewActionRow(discord.NewStringSelectMenu(helpSelectID, i18n.T(locale, "help.select"), options...)),
+		},
+		Flags: discord.MessageFlagEphemeral,
+	}
+}
+
+// availableCommands returns the registered command definitions usable in the current context,
+// adjusted for dev mode like the synced commands.
+// Commands not allowed in guilds or requiring permissions the member lacks are hidden.
+func availableCommands(devMode bool, guildID *snowflake.ID, member *discord.ResolvedMember) []discord.ApplicationCommandCreate {
+	var defs []discord.ApplicationCommandCreate
+	for _, cmd := range registry {
+		def := definition(cmd, devMode)
+		if guildID != nil && !commandEnabledInGuild(def, member) {
+			continue
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// commandEnabledInGuild reports whether a command can be used by the member in a guild
+func commandEnabledInGuild(def discord.ApplicationCommandCreate, member *discord.ResolvedMember) bool {
+	var (
+		contexts    []discord.InteractionContextType
+		permissions discord.Permissions
+	)
+	switch d := def.(type) {
+	case discord.SlashCommandCreate:
+		contexts = d.Contexts
+		if d.DefaultMemberPermissions != nil {
+			permissions = d.DefaultMemberPermissions.Value()
+		}
+	case discord.UserCommandCreate:
+		contexts = d.Contexts
+		if d.DefaultMemberPermissions != nil {
+			permissions = d.DefaultMemberPermissions.Value()
+		}
+	case discord.MessageCommandCreate:
+		contexts = d.Contexts
+		if d.DefaultMemberPermissions != nil {
+			permissions = d.DefaultMemberPermissions.Value()
+		}
+	}
+
+	if len(contexts) > 0 {
+		allowed := false
+		for _, ctx := range contexts {
+			if ctx == discord.InteractionContextTypeGuild {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+
+	if permissions != discord.PermissionsNone && member != nil {
+		return member.Permissions.Has(permissions)
+	}
+	return true
+}
+
+// findCommand looks up a command definition by name
+func findCommand(defs []discord.ApplicationCommandCreate, name string) (discord.ApplicationCommandCreate, bool) {
+	name = strings.TrimPrefix(name, "/")
+	for _, def := range defs {
+		if strings.EqualFold(def.CommandName(), name) {
+			return def, true
+		}
+	}
+	return nil, false
+}
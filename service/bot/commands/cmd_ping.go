@@ -5,6 +5,7 @@ import (
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
 	"github.com/zokiio/mukabi/service/bot/embeds"
+	"github.com/zokiio/mukabi/service/bot/i18n"
 )
 
 type pingCmd struct{}
@@ -14,15 +15,15 @@ func init() {
 }
 
 func (c *pingCmd) Definition() discord.ApplicationCommandCreate {
-	return discord.SlashCommandCreate{
+	return i18n.LocalizeCommand(discord.SlashCommandCreate{
 		Name:        "ping",
 		Description: "Check bot responsiveness",
-	}
+	})
 }
 
 func (c *pingCmd) Handler(cmd *Commander) handler.CommandHandler {
 	return func(e *handler.CommandEvent) error {
-		return e.CreateMessage(embeds.Message("Pong!"))
+		return e.CreateMessage(embeds.Message(i18n.T(e.Locale(), "ping.pong")))
 	}
 }
 
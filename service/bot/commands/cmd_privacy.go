@@ -0,0 +1,93 @@
+// Package commands implements Discord slash command handlers for the bot
+package commands
+
+import (
+	"log/slog"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/topi314/tint"
+	"github.com/zokiio/mukabi/service/bot/embeds"
+	"github.com/zokiio/mukabi/service/bot/i18n"
+)
+
+// Custom IDs of the data deletion confirmation buttons
+const (
+	privacyConfirmID = "/privacy/delete-confirm"
+	privacyCancelID  = "/privacy/delete-cancel"
+)
+
+type privacyCmd struct{}
+
+func init() {
+	RegisterCommand(&privacyCmd{})
+}
+
+func (c *privacyCmd) Definition() discord.ApplicationCommandCreate {
+	return i18n.LocalizeCommand(discord.SlashCommandCreate{
+		Name:        "privacy",
+		Description: "Manage the data the bot stores about you",
+		Options: []discord.ApplicationCommandOption{
+			&discord.ApplicationCommandOptionSubCommand{
+				Name:        "delete-my-data",
+				Description: "Delete all your registered characters on every server",
+			},
+		},
+	})
+}
+
+func (c *privacyCmd) Handler(_ *Commander) handler.CommandHandler {
+	return func(e *handler.CommandEvent) error {
+		subcommand := e.SlashCommandInteractionData().SubCommandName
+		if subcommand == nil || *subcommand != "delete-my-data" {
+			return e.CreateMessage(embeds.Error(e.Locale(), "errors.no_subcommand"))
+		}
+
+		msg := embeds.Message(i18n.T(e.Locale(), "privacy.confirm"))
+		msg.Flags = discord.MessageFlagEphemeral
+		msg.Components = []discord.ContainerComponent{
+			discord.NewActionRow(
+				discord.NewDangerButton(i18n.T(e.Locale(), "privacy.confirm_button"), privacyConfirmID),
+				discord.NewSecondaryButton(i18n.T(e.Locale(), "privacy.cancel_button"), privacyCancelID),
+			),
+		}
+		return e.CreateMessage(msg)
+	}
+}
+
+func (c *privacyCmd) AutocompleteHandler(_ *Commander) handler.AutocompleteHandler {
+	return nil
+}
+
+// ComponentHandlers returns the handlers of the deletion confirmation buttons
+func (c *privacyCmd) ComponentHandlers(cmd *Commander) map[string]handler.ComponentHandler {
+	return map[string]handler.ComponentHandler{
+		privacyConfirmID: cmd.handleDeleteMyData,
+		privacyCancelID: func(e *handler.ComponentEvent) error {
+			return e.UpdateMessage(privacyResult(i18n.T(e.Locale(), "privacy.cancelled")))
+		},
+	}
+}
+
+func (c *Commander) handleDeleteMyData(e *handler.ComponentEvent) error {
+	deleted, err := c.Database.DeleteUserData(e.Ctx, e.User().ID.String())
+	if err != nil {
+		logger().Error("Failed to delete user data", tint.Err(err))
+		return e.CreateMessage(databaseError(e.Locale(), "errors.internal", err))
+	}
+
+	logger().Info("Deleted user data",
+		slog.String("user", e.User().ID.String()),
+		slog.Int64("characters", deleted),
+	)
+	return e.UpdateMessage(privacyResult(i18n.T(e.Locale(), "privacy.deleted", deleted)))
+}
+
+// privacyResult replaces the confirmation prompt with a result message
+func privacyResult(content string) discord.MessageUpdate {
+	msg := embeds.Message(content)
+	return discord.MessageUpdate{
+		Embeds:     &msg.Embeds,
+		Components: &[]discord.ContainerComponent{},
+	}
+}
@@ -2,6 +2,8 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"strings"
 
@@ -9,18 +11,23 @@ import (
 	"github.com/disgoorg/disgo/handler"
 	"github.com/topi314/tint"
 	"github.com/zokiio/mukabi/external/raiderio"
+	"github.com/zokiio/mukabi/internal/wow"
 	"github.com/zokiio/mukabi/service/bot/db"
 	"github.com/zokiio/mukabi/service/bot/embeds"
+	"github.com/zokiio/mukabi/service/bot/i18n"
 )
 
 type wowCmd struct{}
 
+// errCharacterAmbiguous is returned when a typed character name matches characters on several realms
+var errCharacterAmbiguous = errors.New("character name is ambiguous")
+
 func init() {
 	RegisterCommand(&wowCmd{})
 }
 
 func (c *wowCmd) Definition() discord.ApplicationCommandCreate {
-	return discord.SlashCommandCreate{
+	return i18n.LocalizeCommand(discord.SlashCommandCreate{
 		Name:        "wow",
 		Description: "World of Warcraft features and character management",
 		Options: []discord.ApplicationCommandOption{
@@ -32,10 +39,7 @@ func (c *wowCmd) Definition() discord.ApplicationCommandCreate {
 						Name:        "region",
 						Description: "Region of the character",
 						Required:    true,
-						Choices: []discord.ApplicationCommandOptionChoiceString{
-							{Name: "EU", Value: "eu"},
-							{Name: "US", Value: "us"},
-						},
+						Choices:     regionChoices(),
 					},
 					&discord.ApplicationCommandOptionString{
 						Name:         "realm",
@@ -63,7 +67,20 @@ func (c *wowCmd) Definition() discord.ApplicationCommandCreate {
 				},
 			},
 		},
+	})
+}
+
+// regionChoices returns the region option choices for all supported regions
+func regionChoices() []discord.ApplicationCommandOptionChoiceString {
+	regions := wow.Regions()
+	choices := make([]discord.ApplicationCommandOptionChoiceString, len(regions))
+	for i, region := range regions {
+		choices[i] = discord.ApplicationCommandOptionChoiceString{
+			Name:  fmt.Sprintf("%s (%s)", region.Name, strings.ToUpper(region.Code)),
+			Value: region.Code,
+		}
 	}
+	return choices
 }
 
 func (c *wowCmd) Handler(cmd *Commander) handler.CommandHandler {
@@ -71,7 +88,7 @@ func (c *wowCmd) Handler(cmd *Commander) handler.CommandHandler {
 		data := e.SlashCommandInteractionData()
 		subcommand := data.SubCommandName
 		if subcommand == nil {
-			return e.CreateMessage(embeds.Error("No subcommand provided"))
+			return e.CreateMessage(embeds.Error(e.Locale(), "errors.no_subcommand"))
 		}
 
 		switch *subcommand {
@@ -82,14 +99,14 @@ func (c *wowCmd) Handler(cmd *Commander) handler.CommandHandler {
 				return cmd.handleCharacterStats(data, e)
 			})(e)
 		default:
-			return e.CreateMessage(embeds.Error("Unknown WoW subcommand: %s", *subcommand))
+			return e.CreateMessage(embeds.Error(e.Locale(), "errors.unknown_subcommand", *subcommand))
 		}
 	}
 }
 
 func (c *wowCmd) AutocompleteHandler(cmd *Commander) handler.AutocompleteHandler {
 	return func(e *handler.AutocompleteEvent) error {
-		slog.Debug("Processing WoW autocomplete", slog.String("focused_option", e.Data.Focused().Name))
+		logger().Debug("Processing WoW autocomplete", slog.String("focused_option", e.Data.Focused().Name))
 
 		switch *e.Data.SubCommandName {
 		case "reg-character":
@@ -109,75 +126,123 @@ func (c *wowCmd) AutocompleteHandler(cmd *Commander) handler.AutocompleteHandler
 func (c *Commander) handleRegisterCharacter(data discord.SlashCommandInteractionData, e *handler.CommandEvent) error {
 
 	// Check if server exists in database
-	exists, err := c.Database.ServerExists(e.GuildID().String())
+	exists, err := c.Database.ServerExists(e.Ctx, e.GuildID().String())
 	if err != nil {
-		slog.Error("Failed to check server existence", tint.Err(err))
-		return e.CreateMessage(embeds.Error("An internal error occurred. Please try again later."))
+		logger().Error("Failed to check server existence", tint.Err(err))
+		return e.CreateMessage(databaseError(e.Locale(), "errors.internal", err))
 	}
 	if !exists {
-		return e.CreateMessage(embeds.Error("This server is not registered. Please wait a few minutes and try again."))
+		return e.CreateMessage(embeds.Error(e.Locale(), "errors.server_not_registered"))
 	}
 
 	region := data.String("region")
 	realm := data.String("realm")
 	character := data.String("character")
 
+	if _, ok := wow.LookupRegion(region); !ok {
+		return e.CreateMessage(embeds.Error(e.Locale(), "errors.unknown_region", region))
+	}
+
 	characterData, err := c.External.RaiderIO().FetchCharacterProfile(region, realm, character, raiderio.WithFields(
 		raiderio.FieldMythicPlusScoresBySeason,
 	))
 	if err != nil {
-		slog.Warn("Character not found",
+		logger().Warn("Character not found",
 			slog.String("region", region),
 			slog.String("realm", realm),
 			slog.String("character", character),
 			tint.Err(err),
 		)
-		return e.CreateMessage(embeds.Error("Character not found. Please double-check the spelling and try again."))
+		return e.CreateMessage(embeds.Error(e.Locale(), "errors.character_not_found"))
 	}
 
-	if err := c.Database.WoWRegisterCharacter(e.GuildID().String(), e.User().ID.String(), db.WoWCharacter{
-		CharacterName: character,
+	// Store the name spelling and realm slug Raider.IO knows the character by
+	if err := c.Database.WoWRegisterCharacter(e.Ctx, e.GuildID().String(), e.User().ID.String(), db.WoWCharacter{
+		CharacterName: characterData.Name,
 		Region:        region,
-		Realm:         realm,
-	}); err != nil {
-		slog.Error("Failed to register character", tint.Err(err))
-		return e.CreateMessage(embeds.Error("Failed to register character. Please try again later."))
+		Realm:         characterData.RealmSlug(),
+	}); errors.Is(err, db.ErrCharacterExists) {
+		return e.CreateMessage(embeds.Error(e.Locale(), "errors.character_exists"))
+	} else if err != nil {
+		logger().Error("Failed to register character", tint.Err(err))
+		return e.CreateMessage(databaseError(e.Locale(), "errors.register_failed", err))
 	}
-	return e.CreateMessage(embeds.CharacterMessage(characterData))
+	return e.CreateMessage(embeds.CharacterMessage(e.Locale(), characterData))
 }
 
 func (c *Commander) handleCharacterStats(data discord.SlashCommandInteractionData, e *handler.CommandEvent) error {
-	character := data.String("character")
-
-	characterData, err := c.Database.WoWGetCharacter(e.GuildID().String(), e.User().ID.String(), character)
-	if err != nil {
-		slog.Error("Failed to fetch character stats", tint.Err(err))
-		return e.CreateMessage(embeds.Error("Failed to fetch character stats. Please try again later."))
+	characterData, err := c.resolveCharacter(e, data.String("character"))
+	if errors.Is(err, db.ErrCharacterNotFound) {
+		return e.CreateMessage(embeds.Error(e.Locale(), "errors.character_not_found"))
+	} else if errors.Is(err, errCharacterAmbiguous) {
+		return e.CreateMessage(embeds.Error(e.Locale(), "errors.character_ambiguous"))
+	} else if err != nil {
+		logger().Error("Failed to fetch character stats", tint.Err(err))
+		return e.CreateMessage(databaseError(e.Locale(), "errors.stats_failed", err))
 	}
 
 	profile, err := c.External.RaiderIO().FetchCharacterProfile(
 		characterData.Region,
 		characterData.Realm,
-		character,
+		characterData.CharacterName,
 		raiderio.WithFields(raiderio.FieldMythicPlusScoresBySeason),
 	)
 	if err != nil {
-		slog.Warn("Character not found",
+		logger().Warn("Character not found",
 			slog.String("region", characterData.Region),
 			slog.String("realm", characterData.Realm),
-			slog.String("character", character),
+			slog.String("character", characterData.CharacterName),
 			tint.Err(err),
 		)
-		return e.CreateMessage(embeds.Error("Character not found. Please check if the character still exists."))
+		return e.CreateMessage(embeds.Error(e.Locale(), "errors.character_gone"))
+	}
+	return e.CreateMessage(embeds.CharacterMessage(e.Locale(), profile))
+}
+
+// characterChoice returns the autocomplete value identifying a registered character, e.g. "eu/twisting-nether/Zoki"
+func characterChoice(character db.WoWCharacter) string {
+	return character.Region + "/" + character.Realm + "/" + character.CharacterName
+}
+
+// resolveCharacter returns the registered character of the user for a character option value.
+// Values picked from autocomplete identify the character by region, realm and name,
+// typed names must match a single registered character.
+func (c *Commander) resolveCharacter(e *handler.CommandEvent, value string) (db.WoWCharacter, error) {
+	serverID, discordID := e.GuildID().String(), e.User().ID.String()
+	if parts := strings.SplitN(value, "/", 3); len(parts) == 3 {
+		return c.Database.WoWGetCharacter(e.Ctx, serverID, discordID, db.WoWCharacter{
+			CharacterName: parts[2],
+			Region:        parts[0],
+			Realm:         parts[1],
+		})
+	}
+
+	characters, err := c.Database.WoWGetCharacters(e.Ctx, serverID, discordID)
+	if err != nil {
+		return db.WoWCharacter{}, err
+	}
+
+	var matches []db.WoWCharacter
+	for _, character := range characters {
+		if wow.CharacterKey(character.CharacterName) == wow.CharacterKey(value) {
+			matches = append(matches, character)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return db.WoWCharacter{}, db.ErrCharacterNotFound
+	case 1:
+		return matches[0], nil
+	default:
+		return db.WoWCharacter{}, errCharacterAmbiguous
 	}
-	return e.CreateMessage(embeds.CharacterMessage(profile))
 }
 
 func (c *Commander) handleCharacterAutocomplete(e *handler.AutocompleteEvent) error {
 	query := e.Data.String("character")
-	characters, err := c.Database.WoWGetCharacters(e.GuildID().String(), e.User().ID.String())
+	characters, err := c.Database.WoWGetCharacters(e.Ctx, e.GuildID().String(), e.User().ID.String())
 	if err != nil {
-		slog.Error("Failed to fetch registered characters", tint.Err(err))
+		logger().Error("Failed to fetch registered characters", tint.Err(err))
 		return nil
 	}
 
@@ -185,8 +250,8 @@ func (c *Commander) handleCharacterAutocomplete(e *handler.AutocompleteEvent) er
 	for _, character := range characters {
 		if strings.Contains(strings.ToLower(character.CharacterName), strings.ToLower(query)) {
 			choices = append(choices, discord.AutocompleteChoiceString{
-				Name:  character.CharacterName,
-				Value: character.CharacterName,
+				Name:  fmt.Sprintf("%s (%s %s)", character.CharacterName, strings.ToUpper(character.Region), character.Realm),
+				Value: characterChoice(character),
 			})
 
 			if len(choices) >= 25 {
@@ -204,7 +269,7 @@ func (c *Commander) handleRealmAutocomplete(e *handler.AutocompleteEvent) error
 
 	realms, err := c.External.RaiderIO().FetchConnectedRealms(region, query)
 	if err != nil {
-		slog.Error("Failed to fetch connected realms", tint.Err(err))
+		logger().Error("Failed to fetch connected realms", tint.Err(err))
 		return nil
 	}
 
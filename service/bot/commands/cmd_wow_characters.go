@@ -0,0 +1,91 @@
+// Package commands implements Discord slash command handlers for the bot
+package commands
+
+import (
+	"log/slog"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/topi314/tint"
+	"github.com/zokiio/mukabi/external/raiderio"
+	"github.com/zokiio/mukabi/service/bot/embeds"
+	"github.com/zokiio/mukabi/service/bot/i18n"
+)
+
+type wowCharactersCmd struct{}
+
+func init() {
+	RegisterCommand(&wowCharactersCmd{})
+}
+
+func (c *wowCharactersCmd) Definition() discord.ApplicationCommandCreate {
+	return i18n.LocalizeCommand(discord.UserCommandCreate{
+		Name:     "WoW Characters",
+		Contexts: []discord.InteractionContextType{discord.InteractionContextTypeGuild},
+	})
+}
+
+func (c *wowCharactersCmd) Handler(cmd *Commander) handler.CommandHandler {
+	return func(e *handler.CommandEvent) error {
+		return cmd.handleViewCharacters(e)
+	}
+}
+
+func (c *wowCharactersCmd) AutocompleteHandler(_ *Commander) handler.AutocompleteHandler {
+	return nil
+}
+
+func (c *Commander) handleViewCharacters(e *handler.CommandEvent) error {
+	// Commands synced before they were limited to guilds can still be invoked in DMs
+	guildID := e.GuildID()
+	if guildID == nil {
+		return e.CreateMessage(embeds.Error(e.Locale(), "errors.guild_only"))
+	}
+	target := e.UserCommandInteractionData().TargetUser()
+
+	characters, err := c.Database.WoWGetCharacters(e.Ctx, guildID.String(), target.ID.String())
+	if err != nil {
+		logger().Error("Failed to fetch registered characters", tint.Err(err))
+		return e.CreateMessage(databaseError(e.Locale(), "errors.characters_failed", err))
+	}
+
+	// Fetching scores may take longer than the interaction deadline
+	if err := e.DeferCreateMessage(true); err != nil {
+		return err
+	}
+
+	summaries := make([]embeds.CharacterSummary, 0, len(characters))
+	for _, character := range characters {
+		summary := embeds.CharacterSummary{
+			Name:   character.CharacterName,
+			Region: character.Region,
+			Realm:  character.Realm,
+		}
+
+		profile, err := c.External.RaiderIO().FetchCharacterProfile(
+			character.Region,
+			character.Realm,
+			character.CharacterName,
+			raiderio.WithFields(raiderio.FieldMythicPlusScoresBySeason),
+		)
+		if err != nil {
+			logger().Warn("Character not found",
+				slog.String("region", character.Region),
+				slog.String("realm", character.Realm),
+				slog.String("character", character.CharacterName),
+				tint.Err(err),
+			)
+		} else {
+			summary.Found = true
+			summary.Score = embeds.CurrentScore(profile)
+			summary.ProfileURL = profile.ProfileURL
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	_, err = e.UpdateInteractionResponse(discord.MessageUpdate{
+		Embeds: &[]discord.Embed{embeds.CharacterList(e.Locale(), target.EffectiveName(), summaries)},
+	})
+	return err
+}
@@ -2,43 +2,105 @@
 package commands
 
 import (
+	"log/slog"
+
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
-	"github.com/disgoorg/disgo/handler/middleware"
 
+	"github.com/zokiio/mukabi/internal/log"
 	"github.com/zokiio/mukabi/service/bot"
+	"github.com/zokiio/mukabi/service/bot/db"
+	"github.com/zokiio/mukabi/service/bot/embeds"
 )
 
-// Commander handles Discord slash command interactions
+// logger returns the logger of the commands package, configurable as "commands" in [log.levels]
+func logger() *slog.Logger {
+	return log.Named("commands")
+}
+
+// Commander handles Discord application command interactions
 type Commander struct {
 	*bot.Bot
 }
 
-// Commands returns all registered slash commands for the bot
-func Commands() []discord.ApplicationCommandCreate {
+// devPrefix marks commands synced in dev mode so they are distinguishable from production ones
+const devPrefix = "[DEV] "
+
+// Commands returns all registered application commands for the bot.
+// In dev mode slash command descriptions and context-menu command names are prefixed.
+func Commands(devMode bool) []discord.ApplicationCommandCreate {
 	cmds := make([]discord.ApplicationCommandCreate, len(registry))
 	for i, cmd := range registry {
-		cmds[i] = cmd.Definition()
+		cmds[i] = definition(cmd, devMode)
 	}
 	return cmds
 }
 
+// definition returns the definition of a command, adjusted for dev mode if enabled
+func definition(cmd Command, devMode bool) discord.ApplicationCommandCreate {
+	def := cmd.Definition()
+	if !devMode {
+		return def
+	}
+
+	// Slash command names are kept so command paths stay stable
+	switch d := def.(type) {
+	case discord.SlashCommandCreate:
+		d.Description = devPrefix + d.Description
+		for locale, description := range d.DescriptionLocalizations {
+			d.DescriptionLocalizations[locale] = devPrefix + description
+		}
+		return d
+	case discord.UserCommandCreate:
+		d.Name = devPrefix + d.Name
+		d.NameLocalizations = nil
+		return d
+	case discord.MessageCommandCreate:
+		d.Name = devPrefix + d.Name
+		d.NameLocalizations = nil
+		return d
+	default:
+		return def
+	}
+}
+
 // New creates a new command router with all registered commands and middlewares
 func New(b *bot.Bot) handler.Router {
 	cmds := &Commander{b}
 	router := handler.New()
-	router.Use(middleware.Go)
+	router.DefaultContext(b.Context)
+	router.Use(cmds.goMiddleware, metricsMiddleware)
 
 	// Register all commands from the registry
 	for _, cmd := range registry {
-		def := cmd.Definition().(discord.SlashCommandCreate)
+		def := definition(cmd, b.Config.Bot.DevMode)
 		if handler := cmd.Handler(cmds); handler != nil {
-			router.Command("/"+def.Name, handler)
+			router.Command("/"+def.CommandName(), handler)
+		}
+
+		if components, ok := cmd.(ComponentCommand); ok {
+			for customID, componentHandler := range components.ComponentHandlers(cmds) {
+				router.Component(customID, componentHandler)
+			}
+		}
+
+		// Only slash commands can have autocomplete options
+		if _, ok := def.(discord.SlashCommandCreate); !ok {
+			continue
 		}
 		if autoHandler := cmd.AutocompleteHandler(cmds); autoHandler != nil {
-			router.Autocomplete("/"+def.Name, autoHandler)
+			router.Autocomplete("/"+def.CommandName(), autoHandler)
 		}
 	}
 
 	return router
 }
+
+// databaseError returns the error message for a failed database call.
+// Timeouts get a dedicated message asking the user to retry instead of the given key.
+func databaseError(locale discord.Locale, key string, err error) discord.MessageCreate {
+	if db.IsTimeout(err) {
+		return embeds.Error(locale, "errors.database_busy")
+	}
+	return embeds.Error(locale, key)
+}
@@ -0,0 +1,290 @@
+package commands_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/zokiio/mukabi/external"
+	"github.com/zokiio/mukabi/external/raiderio/fake"
+	"github.com/zokiio/mukabi/internal/fakediscord"
+	"github.com/zokiio/mukabi/service/bot"
+	"github.com/zokiio/mukabi/service/bot/commands"
+	"github.com/zokiio/mukabi/service/bot/db"
+	"github.com/zokiio/mukabi/service/bot/db/memory"
+	"github.com/zokiio/mukabi/service/bot/events"
+	"github.com/zokiio/mukabi/service/bot/i18n"
+)
+
+// harness is a bot running against the fake Discord server, the fake Raider.IO client and the in-memory store
+type harness struct {
+	config  bot.Config
+	discord *fakediscord.Server
+	store   *memory.Store
+	guildID snowflake.ID
+	user    fakediscord.User
+}
+
+// startBot starts a bot in a single guild and waits until the guild is registered.
+// Commands are synced to the guild unless changed by configure.
+func startBot(t *testing.T, configure ...func(*bot.Config)) *harness {
+	t.Helper()
+
+	discord := fakediscord.New()
+	t.Cleanup(discord.Close)
+
+	guildID := snowflake.New(time.Now())
+	if err := discord.AddGuild(guildID, "Guild"); err != nil {
+		t.Fatalf("failed to add guild: %s", err)
+	}
+
+	raiderIO, err := fake.NewWithFixtures()
+	if err != nil {
+		t.Fatalf("failed to load Raider.IO fixtures: %s", err)
+	}
+
+	var cfg bot.Config
+	cfg.Bot.GatewayURL, cfg.Bot.RestURL, cfg.Bot.Token = discord.GatewayURL(), discord.RestURL(), discord.Token
+	cfg.Bot.SyncCommands = true
+	cfg.Bot.GuildIDs = []snowflake.ID{guildID}
+	for _, fn := range configure {
+		fn(&cfg)
+	}
+
+	store := memory.New()
+	b, err := bot.New(cfg, "test", "test",
+		bot.WithStore(store),
+		bot.WithExternal(external.New(external.WithRaiderIO(raiderIO))),
+	)
+	if err != nil {
+		t.Fatalf("failed to create bot: %s", err)
+	}
+	t.Cleanup(b.Close)
+
+	b.Discord.AddEventListeners(commands.New(b), events.New(b))
+	if err := b.Start(commands.Commands(false)); err != nil {
+		t.Fatalf("failed to start bot: %s", err)
+	}
+
+	// Commands require the guild to be registered by the event handlers
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for {
+		exists, err := store.ServerExists(ctx, guildID.String())
+		if err != nil {
+			t.Fatalf("guild was not registered: %s", err)
+		}
+		if exists {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return &harness{
+		config:  cfg,
+		discord: discord,
+		store:   store,
+		guildID: guildID,
+		user:    fakediscord.User{ID: snowflake.New(time.Now()), Username: "zoki"},
+	}
+}
+
+// wow sends a /wow subcommand and returns the single response of the bot
+func (h *harness) wow(t *testing.T, subcommand string, options ...fakediscord.Option) fakediscord.Response {
+	t.Helper()
+	return h.respond(t, h.discord.SendSlashCommand, subcommand, options...)
+}
+
+// autocomplete sends a /wow autocomplete interaction and returns the single response of the bot
+func (h *harness) autocomplete(t *testing.T, subcommand string, options ...fakediscord.Option) fakediscord.Response {
+	t.Helper()
+	return h.respond(t, h.discord.SendAutocomplete, subcommand, options...)
+}
+
+func (h *harness) respond(t *testing.T, send func(fakediscord.SlashCommand) (*fakediscord.Interaction, error), subcommand string, options ...fakediscord.Option) fakediscord.Response {
+	t.Helper()
+
+	interaction, err := send(fakediscord.SlashCommand{
+		GuildID:    h.guildID,
+		User:       h.user,
+		Name:       "wow",
+		Subcommand: subcommand,
+		Options:    options,
+	})
+	if err != nil {
+		t.Fatalf("failed to send %s: %s", subcommand, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	response, err := interaction.Next(ctx)
+	if err != nil {
+		t.Fatalf("%s: %s", subcommand, err)
+	}
+	if responses := interaction.Responses(); len(responses) != 1 {
+		t.Errorf("%s: got %d responses, want 1", subcommand, len(responses))
+	}
+	return response
+}
+
+// assertError checks that the response is an ephemeral error with the message of the given key
+func assertError(t *testing.T, response fakediscord.Response, key string) {
+	t.Helper()
+	message := response.Message
+	if len(message.Embeds) != 1 || message.Embeds[0].Description != i18n.T(discord.LocaleEnglishUS, key) {
+		t.Errorf("response = %+v, want error %s", message.Embeds, key)
+	}
+	if !message.Ephemeral() {
+		t.Errorf("error %s is not ephemeral", key)
+	}
+}
+
+// assertCharacter checks that the response shows the profile of the named character
+func assertCharacter(t *testing.T, response fakediscord.Response, name string) {
+	t.Helper()
+	if response.Kind != fakediscord.ResponseCallback || response.Type != discord.InteractionResponseTypeCreateMessage {
+		t.Errorf("response = %s %d, want callback with a message", response.Kind, response.Type)
+	}
+	if embeds := response.Message.Embeds; len(embeds) != 1 || embeds[0].Title != name {
+		t.Errorf("response embeds = %+v, want profile of %s", embeds, name)
+	}
+}
+
+func TestRegisterCharacterAndStats(t *testing.T) {
+	h := startBot(t)
+
+	// The name and realm are stored as Raider.IO knows them
+	response := h.wow(t, "reg-character",
+		fakediscord.Option{Name: "region", Value: "eu"},
+		fakediscord.Option{Name: "realm", Value: "Twisting Nether"},
+		fakediscord.Option{Name: "character", Value: "zoki"},
+	)
+	assertCharacter(t, response, "Zoki")
+
+	characters, err := h.store.WoWGetCharacters(context.Background(), h.guildID.String(), h.user.ID.String())
+	if err != nil {
+		t.Fatalf("failed to read characters: %s", err)
+	}
+	want := db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "twisting-nether"}
+	if len(characters) != 1 || characters[0] != want {
+		t.Errorf("stored characters = %+v, want [%+v]", characters, want)
+	}
+
+	// Names differing in case are the same character
+	response = h.wow(t, "reg-character",
+		fakediscord.Option{Name: "region", Value: "eu"},
+		fakediscord.Option{Name: "realm", Value: "twisting-nether"},
+		fakediscord.Option{Name: "character", Value: "ZOKI"},
+	)
+	assertError(t, response, "errors.character_exists")
+
+	// Autocomplete identifies the character by region, realm and name
+	response = h.autocomplete(t, "char-stats", fakediscord.Option{Name: "character", Value: "zo", Focused: true})
+	if choices := response.Message.Choices; len(choices) != 1 || choices[0].Value != "eu/twisting-nether/Zoki" {
+		t.Errorf("autocomplete choices = %+v, want eu/twisting-nether/Zoki", choices)
+	}
+
+	response = h.wow(t, "char-stats", fakediscord.Option{Name: "character", Value: "eu/twisting-nether/Zoki"})
+	assertCharacter(t, response, "Zoki")
+
+	// Typed names are matched case-insensitively
+	response = h.wow(t, "char-stats", fakediscord.Option{Name: "character", Value: "zOkI"})
+	assertCharacter(t, response, "Zoki")
+
+	response = h.wow(t, "char-stats", fakediscord.Option{Name: "character", Value: "nobody"})
+	assertError(t, response, "errors.character_not_found")
+}
+
+func TestRegisterUnknownCharacter(t *testing.T) {
+	h := startBot(t)
+
+	response := h.wow(t, "reg-character",
+		fakediscord.Option{Name: "region", Value: "eu"},
+		fakediscord.Option{Name: "realm", Value: "twisting-nether"},
+		fakediscord.Option{Name: "character", Value: "nobody"},
+	)
+	assertError(t, response, "errors.character_not_found")
+
+	registered, err := h.store.WoWHasRegisteredCharacter(context.Background(), h.guildID.String(), h.user.ID.String())
+	if err != nil || registered {
+		t.Errorf("WoWHasRegisteredCharacter() = %t, %v, want false", registered, err)
+	}
+}
+
+func TestCommandsUpToDateAfterSync(t *testing.T) {
+	h := startBot(t)
+
+	// Registered commands carry the fields Discord fills in, which must not count as changes
+	syncer, err := bot.NewCommandSyncer(h.config)
+	if err != nil {
+		t.Fatalf("failed to create command syncer: %s", err)
+	}
+	defer syncer.Close()
+
+	changes, err := syncer.Plan(commands.Commands(false))
+	if err != nil {
+		t.Fatalf("Plan() error = %s", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Plan() after sync = %v, want no changes", changes)
+	}
+}
+
+func TestViewCharactersInDM(t *testing.T) {
+	// Only global commands are available in DMs
+	h := startBot(t, func(cfg *bot.Config) { cfg.Bot.GuildIDs = nil })
+
+	h.wow(t, "reg-character",
+		fakediscord.Option{Name: "region", Value: "eu"},
+		fakediscord.Option{Name: "realm", Value: "twisting-nether"},
+		fakediscord.Option{Name: "character", Value: "Zoki"},
+	)
+
+	registered := h.discord.Commands(0)
+	i := slices.IndexFunc(registered, func(c discord.ApplicationCommand) bool { return c.Name() == "WoW Characters" })
+	if i < 0 {
+		t.Fatal("WoW Characters is not registered globally")
+	}
+	if contexts := registered[i].(discord.UserCommand).Contexts(); !slices.Equal(contexts, []discord.InteractionContextType{discord.InteractionContextTypeGuild}) {
+		t.Errorf("WoW Characters contexts = %v, want guild only", contexts)
+	}
+
+	// Commands registered before they were limited to guilds can still be invoked in DMs
+	message := h.viewCharacters(t, 0)
+	if len(message.Embeds) != 1 || message.Embeds[0].Description != i18n.T(discord.LocaleEnglishUS, "errors.guild_only") {
+		t.Errorf("DM response = %+v, want error errors.guild_only", message.Embeds)
+	}
+
+	// The bot keeps handling interactions afterwards
+	message = h.viewCharacters(t, h.guildID)
+	if len(message.Embeds) != 1 || message.Embeds[0].Title != i18n.T(discord.LocaleEnglishUS, "character.list_title", h.user.Username) {
+		t.Errorf("guild response = %+v, want the character list of %s", message.Embeds, h.user.Username)
+	}
+}
+
+// viewCharacters invokes the WoW Characters user command on the harness user and returns the
+// message the user ends up seeing. A zero guild ID invokes it in a DM with the bot.
+func (h *harness) viewCharacters(t *testing.T, guildID snowflake.ID) fakediscord.Message {
+	t.Helper()
+
+	interaction, err := h.discord.SendUserCommand(fakediscord.UserCommand{
+		GuildID: guildID,
+		User:    h.user,
+		Name:    "WoW Characters",
+		Target:  h.user,
+	})
+	if err != nil {
+		t.Fatalf("failed to send WoW Characters: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	message, err := interaction.Last(ctx)
+	if err != nil {
+		t.Fatalf("WoW Characters: %s", err)
+	}
+	return message
+}
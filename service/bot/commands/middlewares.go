@@ -3,8 +3,12 @@ package commands
 
 import (
 	"log/slog"
+	"time"
 
+	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
+	"github.com/topi314/tint"
+	"github.com/zokiio/mukabi/internal/metrics"
 	"github.com/zokiio/mukabi/service/bot/embeds"
 )
 
@@ -14,26 +18,67 @@ func (c *Commander) wrapWowMiddleware(next func(e *handler.CommandEvent) error)
 		userID := e.User().ID.String()
 		guildID := e.GuildID().String()
 
-		slog.Info("Checking character registration",
+		logger().Info("Checking character registration",
 			slog.String("user", userID),
 			slog.String("guild", guildID),
 		)
 
-		hasCharacter, err := c.Database.WoWHasRegisteredCharacter(guildID, userID)
+		hasCharacter, err := c.Database.WoWHasRegisteredCharacter(e.Ctx, guildID, userID)
 		if err != nil {
-			return e.CreateMessage(embeds.Error("Failed to check character registration"))
+			logger().Error("Failed to check character registration", tint.Err(err))
+			return e.CreateMessage(databaseError(e.Locale(), "errors.registration_check_failed", err))
 		}
 
-		slog.Debug("Character registration check result",
+		logger().Debug("Character registration check result",
 			slog.String("user", userID),
 			slog.String("guild", guildID),
 			slog.Bool("hasCharacter", hasCharacter),
 		)
 
 		if !hasCharacter {
-			return e.CreateMessage(embeds.Error("No character found. Please register a character using /wow reg-character"))
+			return e.CreateMessage(embeds.Error(e.Locale(), "errors.no_character"))
 		}
 
 		return next(e)
 	}
 }
+
+// goMiddleware runs handlers in goroutines tracked by the bot so shutdown can drain them
+func (c *Commander) goMiddleware(next handler.Handler) handler.Handler {
+	return func(e *handler.InteractionEvent) error {
+		started := c.Go(func() {
+			if err := next(e); err != nil {
+				logger().Error("Failed to handle interaction", tint.Err(err))
+			}
+		})
+		if !started {
+			logger().Warn("Dropping interaction received during shutdown")
+		}
+		return nil
+	}
+}
+
+// metricsMiddleware records the count and latency of every handled interaction
+func metricsMiddleware(next handler.Handler) handler.Handler {
+	return func(e *handler.InteractionEvent) error {
+		start := time.Now()
+		err := next(e)
+
+		var command, interactionType string
+		switch i := e.Interaction.(type) {
+		case discord.ApplicationCommandInteraction:
+			command, interactionType = i.Data.CommandName(), "command"
+		case discord.AutocompleteInteraction:
+			command, interactionType = i.Data.CommandName, "autocomplete"
+		case discord.ComponentInteraction:
+			command, interactionType = i.Data.CustomID(), "component"
+		case discord.ModalSubmitInteraction:
+			command, interactionType = i.Data.CustomID, "modal"
+		default:
+			command, interactionType = "unknown", "unknown"
+		}
+
+		metrics.ObserveInteraction(command, interactionType, start, err)
+		return err
+	}
+}
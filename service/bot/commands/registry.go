@@ -6,15 +6,24 @@ import (
 	"github.com/disgoorg/disgo/handler"
 )
 
-// Command represents a slash command with its definition and handlers
+// Command represents an application command with its definition and handlers.
+// Definitions may be slash commands or user/message context-menu commands.
 type Command interface {
-	// Definition returns the slash command creation structure
-	Definition() discord.ApplicationCommandCreate // Handler returns the command handler function
+	// Definition returns the application command creation structure
+	Definition() discord.ApplicationCommandCreate
+	// Handler returns the command handler function
 	Handler(c *Commander) handler.CommandHandler
-	// AutocompleteHandler returns the autocomplete handler function, if any
+	// AutocompleteHandler returns the autocomplete handler function, if any.
+	// It is ignored for non-slash commands.
 	AutocompleteHandler(c *Commander) handler.AutocompleteHandler
 }
 
+// ComponentCommand is implemented by commands that also handle message components.
+// The returned map is keyed by component custom ID.
+type ComponentCommand interface {
+	ComponentHandlers(c *Commander) map[string]handler.ComponentHandler
+}
+
 // registry holds all registered commands
 var registry []Command
 
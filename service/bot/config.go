@@ -4,52 +4,107 @@ package bot
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/disgoorg/snowflake/v2"
 	"github.com/zokiio/mukabi/internal/log"
 	"github.com/zokiio/mukabi/service/bot/db"
 )
 
+const defaultRaiderIOCacheTTL = time.Hour
+
 // Config holds all configuration settings for the bot
 type Config struct {
-	Log      log.Config     `toml:"log"`
-	Bot      BotConfig      `toml:"bot"`
-	Database db.Config      `toml:"database"`
-	External ExternalConfig `toml:"external"`
+	Log       log.Config      `toml:"log"`
+	Bot       BotConfig       `toml:"bot"`
+	Database  db.Config       `toml:"database"`
+	External  ExternalConfig  `toml:"external"`
+	Retention RetentionConfig `toml:"retention"`
+	HTTP      HTTPConfig      `toml:"http"`
 }
 
 // String returns a string representation of the configuration, masking sensitive data
 func (c Config) String() string {
-	return fmt.Sprintf("\n Log: %v\n Bot: %s\n Database: %s\n",
+	return fmt.Sprintf("\n Log: %v\n Bot: %s\n Database: %s\n External: %s\n Retention: %s\n HTTP: %s\n",
 		c.Log,
 		c.Bot,
 		c.Database,
+		c.External,
+		c.Retention,
+		c.HTTP,
 	)
 }
 
+// LogOptions returns the logging options derived from the configuration,
+// used to post logs to a Discord channel and to redact secrets from them
+func (c Config) LogOptions() []log.Option {
+	return []log.Option{
+		log.WithBotToken(c.Bot.Token),
+		log.WithSecrets(append(c.Database.Secrets(), c.External.RaiderIOKey)...),
+	}
+}
+
 // BotConfig holds Discord-specific configuration
 type BotConfig struct {
+	DevMode      bool           `toml:"dev_mode"`
 	SyncCommands bool           `toml:"sync_commands"`
 	GuildIDs     []snowflake.ID `toml:"guild_ids"`
 	GatewayURL   string         `toml:"gateway_url"`
 	RestURL      string         `toml:"rest_url"`
 	Token        string         `toml:"token"`
+
+	ShutdownTimeout time.Duration `toml:"shutdown_timeout"` // Maximum time to wait for in-flight work on shutdown
 }
 
 // String returns a string representation of the bot configuration, masking sensitive data
 func (c BotConfig) String() string {
-	return fmt.Sprintf("\n  SyncCommands: %t\n  GuildIDs: %v\n  GatewayURL: %s\n  RestURL: %s\n  Token: %s\n",
+	return fmt.Sprintf("\n  DevMode: %t\n  SyncCommands: %t\n  GuildIDs: %v\n  GatewayURL: %s\n  RestURL: %s\n  Token: %s\n  ShutdownTimeout: %s\n",
+		c.DevMode,
 		c.SyncCommands,
 		c.GuildIDs,
 		c.GatewayURL,
 		c.RestURL,
 		strings.Repeat("*", len(c.Token)),
+		c.ShutdownTimeout,
 	)
 }
 
 // ExternalConfig holds configuration for external services
 type ExternalConfig struct {
-	RaiderIOKey string `toml:"raiderio_key"`
+	RaiderIOKey      string        `toml:"raiderio_key"`
+	RaiderIOCacheTTL time.Duration `toml:"raiderio_cache_ttl"` // How long Raider.IO responses are cached, 0 uses the default
+}
+
+// String returns a string representation of the external configuration, masking sensitive data
+func (c ExternalConfig) String() string {
+	return fmt.Sprintf("\n  RaiderIOKey: %s\n  RaiderIOCacheTTL: %s\n",
+		strings.Repeat("*", len(c.RaiderIOKey)),
+		c.RaiderIOCacheTTL,
+	)
+}
+
+// raiderIOCacheTTL returns the configured cache TTL or the default if unset
+func (c ExternalConfig) raiderIOCacheTTL() time.Duration {
+	if c.RaiderIOCacheTTL <= 0 {
+		return defaultRaiderIOCacheTTL
+	}
+	return c.RaiderIOCacheTTL
+}
+
+// RetentionConfig holds data retention settings for departed guilds and members
+type RetentionConfig struct {
+	PurgeAfterDays int           `toml:"purge_after_days"` // Days before soft-deleted data is purged, 0 disables purging
+	PurgeInterval  time.Duration `toml:"purge_interval"`   // How often the purge job runs
+	TrackMembers   bool          `toml:"track_members"`    // Soft-delete characters when members leave (requires the privileged members intent)
+}
+
+// String returns a string representation of the retention configuration
+func (c RetentionConfig) String() string {
+	return fmt.Sprintf("\n  PurgeAfterDays: %d\n  PurgeInterval: %s\n  TrackMembers: %t\n",
+		c.PurgeAfterDays,
+		c.PurgeInterval,
+		c.TrackMembers,
+	)
 }
 
 // DBConfig holds database-specific configuration
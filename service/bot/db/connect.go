@@ -0,0 +1,74 @@
+// Package db provides connection handling for the database
+package db
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/topi314/tint"
+)
+
+const (
+	initialConnectBackoff = 500 * time.Millisecond
+	maxConnectBackoff     = 10 * time.Second
+)
+
+// connect pings the database until it is reachable, doubling the wait between attempts.
+// Each attempt is bounded by pingTimeout and retries stop once ctx is done.
+func connect(ctx context.Context, db *sqlx.DB, pingTimeout time.Duration) error {
+	backoff := initialConnectBackoff
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err := db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				logger().Info("Connected to database", slog.Int("attempts", attempt))
+			}
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to ping database after %d attempts: %w", attempt, err)
+		}
+
+		logger().Warn("Database not reachable, retrying",
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", backoff),
+			tint.Err(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to ping database after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+// quoteDSNValue quotes a value for a key/value DSN if it is empty or contains spaces,
+// quotes or backslashes, escaping quotes and backslashes
+func quoteDSNValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`+"\t\n") {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// redactDSN masks the password of a connection URL. Key/value DSNs are masked completely
+// since they cannot be redacted reliably.
+func redactDSN(dsn string) string {
+	if dsn == "" {
+		return ""
+	}
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		return u.Redacted()
+	}
+	return strings.Repeat("*", len(dsn))
+}
@@ -3,21 +3,43 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"github.com/zokiio/mukabi/internal/log"
+	"github.com/zokiio/mukabi/internal/metrics"
 )
 
 const (
-	defaultTimeout = 10 * time.Second
+	defaultTimeout        = 10 * time.Second
+	defaultQueryTimeout   = 5 * time.Second
+	defaultConnectTimeout = time.Minute
+	// Reconciling all servers of a large bot takes longer than a single query
+	reconcileTimeout = time.Minute
 )
 
+// Supported database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// logger returns the logger of the db package, configurable as "db" in [log.levels]
+func logger() *slog.Logger {
+	return log.Named("db")
+}
+
 // Config holds database configuration parameters
 type Config struct {
+	// Connection URL or key/value DSN, replaces the discrete connection fields below if set
+	URL      string `toml:"url"`
 	Host     string `toml:"host"`
 	Port     int    `toml:"port"`
 	Username string `toml:"username"`
@@ -25,45 +47,139 @@ type Config struct {
 	Database string `toml:"database"`
 	SSLMode  string `toml:"ssl_mode"`
 	Driver   string `toml:"driver"`
+	// Maximum duration of a single query, 0 uses the default
+	QueryTimeout time.Duration `toml:"query_timeout"`
+	// How long to retry connecting on startup, 0 uses the default
+	ConnectTimeout time.Duration `toml:"connect_timeout"`
+
+	// Connection pool limits, 0 keeps the driver default
+	MaxOpenConns    int           `toml:"max_open_conns"`
+	MaxIdleConns    int           `toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `toml:"conn_max_lifetime"`
 }
 
 // String returns a string representation of the config, masking sensitive data
 func (c Config) String() string {
-	return fmt.Sprintf("\n   Host: %s\n   Port: %d\n   Username: %s\n   Password: %s\n   Database: %s\n   SSLMode: %s",
+	return fmt.Sprintf("\n   URL: %s\n   Host: %s\n   Port: %d\n   Username: %s\n   Password: %s\n   Database: %s\n   SSLMode: %s\n   QueryTimeout: %s\n   ConnectTimeout: %s\n   MaxOpenConns: %d\n   MaxIdleConns: %d\n   ConnMaxLifetime: %s",
+		redactDSN(c.URL),
 		c.Host,
 		c.Port,
 		c.Username,
 		strings.Repeat("*", len(c.Password)),
 		c.Database,
 		c.SSLMode,
+		c.QueryTimeout,
+		c.ConnectTimeout,
+		c.MaxOpenConns,
+		c.MaxIdleConns,
+		c.ConnMaxLifetime,
 	)
 }
 
-// PostgresDataSourceName returns the PostgreSQL connection string
+// Validate checks that the connection settings are complete and the pool limits are valid
+func (c Config) Validate() error {
+	if c.Driver != DriverPostgres && c.Driver != DriverSQLite {
+		return fmt.Errorf("unsupported database driver %q, expected %s or %s", c.Driver, DriverPostgres, DriverSQLite)
+	}
+
+	if c.URL == "" && c.Database == "" {
+		return fmt.Errorf("database name/path is required")
+	}
+
+	if c.QueryTimeout < 0 || c.ConnectTimeout < 0 {
+		return fmt.Errorf("database timeouts must not be negative")
+	}
+
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 || c.ConnMaxLifetime < 0 {
+		return fmt.Errorf("database pool limits must not be negative")
+	}
+
+	return nil
+}
+
+// Secrets returns the credentials contained in the config, so they can be redacted from logs
+func (c Config) Secrets() []string {
+	secrets := []string{c.Password}
+	if c.URL != "" && c.Driver == DriverPostgres {
+		if pgCfg, err := pgx.ParseConfig(c.URL); err == nil {
+			secrets = append(secrets, pgCfg.Password)
+		}
+	}
+	return secrets
+}
+
+// queryTimeout returns the configured query timeout or the default if unset
+func (c Config) queryTimeout() time.Duration {
+	if c.QueryTimeout <= 0 {
+		return defaultQueryTimeout
+	}
+	return c.QueryTimeout
+}
+
+// IsTimeout reports whether err was caused by a query exceeding its deadline,
+// either the default query timeout or one set on the caller's context
+func IsTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// connectTimeout returns the configured connect timeout or the default if unset
+func (c Config) connectTimeout() time.Duration {
+	if c.ConnectTimeout <= 0 {
+		return defaultConnectTimeout
+	}
+	return c.ConnectTimeout
+}
+
+// PostgresDataSourceName returns the PostgreSQL connection string.
+// The URL is returned as is if set, otherwise a key/value DSN is built with quoted values.
 func (c Config) PostgresDataSourceName() string {
+	if c.URL != "" {
+		return c.URL
+	}
+
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		c.Host,
+		quoteDSNValue(c.Host),
 		c.Port,
-		c.Username,
-		c.Password,
-		c.Database,
-		c.SSLMode,
+		quoteDSNValue(c.Username),
+		quoteDSNValue(c.Password),
+		quoteDSNValue(c.Database),
+		quoteDSNValue(c.SSLMode),
 	)
 }
 
-// New creates a new database connection based on the provided configuration
+// New creates a new database connection based on the provided configuration.
+// It waits up to the connect timeout for the database to become reachable.
+// The schema must be written for the given driver.
 func New(driver string, cfg Config, schema string) (*Database, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.connectTimeout())
 	defer cancel()
-	return newPostgres(ctx, cfg, schema)
+
+	var (
+		db  *sqlx.DB
+		err error
+	)
+	switch driver {
+	case DriverPostgres:
+		db, err = openPostgres(cfg)
+	case DriverSQLite:
+		db, err = openSQLite(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return setup(ctx, db, driver, cfg, schema)
 }
 
 // Database represents a database connection with query capabilities
 type Database struct {
-	db *sqlx.DB
+	db           *sqlx.DB
+	driver       string
+	queryTimeout time.Duration
 }
 
-func newPostgres(ctx context.Context, cfg Config, schema string) (*Database, error) {
+func openPostgres(cfg Config) (*sqlx.DB, error) {
 	pgCfg, err := pgx.ParseConfig(cfg.PostgresDataSourceName())
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse postgres config: %w", err)
@@ -73,39 +189,175 @@ func newPostgres(ctx context.Context, cfg Config, schema string) (*Database, err
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	return db, nil
+}
+
+// setup applies the pool limits, waits for the database and applies the schema.
+// The database is closed if any step fails.
+func setup(ctx context.Context, db *sqlx.DB, driver string, cfg Config, schema string) (*Database, error) {
 
-	if err = db.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+
+	if err := connect(ctx, db, cfg.queryTimeout()); err != nil {
+		db.Close()
+		return nil, err
 	}
 
 	if schema != "" {
-		if _, err = db.ExecContext(ctx, schema); err != nil {
+		schemaCtx, cancel := context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+		if _, err := db.ExecContext(schemaCtx, schema); err != nil {
+			db.Close()
 			return nil, fmt.Errorf("failed to execute schema: %w", err)
 		}
+		// SQLite databases are always created with the current schema
+		if driver == DriverPostgres {
+			if err := migrate(schemaCtx, db); err != nil {
+				db.Close()
+				return nil, err
+			}
+		}
 	}
 
-	return &Database{db: db}, nil
+	return &Database{db: db, driver: driver, queryTimeout: cfg.queryTimeout()}, nil
 }
 
+// Close closes the database connection
 func (d *Database) Close() error {
 	return d.db.Close()
 }
 
-// RegisterServer ensures a server exists in the database
-func (d *Database) RegisterServer(serverID, serverName string) error {
-	_, err := d.db.Exec(
+// Ping verifies the database connection is alive
+func (d *Database) Ping(ctx context.Context) error {
+	ctx, done := d.query(ctx, "ping")
+	defer done()
+
+	return d.db.PingContext(ctx)
+}
+
+// query records metrics for the named query and applies the query timeout to ctx.
+// The returned function must be called once the query has finished.
+func (d *Database) query(ctx context.Context, name string) (context.Context, func()) {
+	return d.queryWithTimeout(ctx, name, d.queryTimeout)
+}
+
+// queryWithTimeout is like query, but bounds the query by the given timeout
+func (d *Database) queryWithTimeout(ctx context.Context, name string, timeout time.Duration) (context.Context, func()) {
+	observe := metrics.ObserveQuery(name)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		observe()
+	}
+}
+
+// Server represents a Discord server the bot is a member of
+type Server struct {
+	ID   string
+	Name string
+}
+
+// RegisterServer ensures a server exists in the database and clears its left marker
+func (d *Database) RegisterServer(ctx context.Context, serverID, serverName string) error {
+	ctx, done := d.query(ctx, "register_server")
+	defer done()
+
+	_, err := d.db.ExecContext(ctx,
 		`INSERT INTO servers (server_id, server_name) 
 		VALUES ($1, $2) 
 		ON CONFLICT (server_id) DO UPDATE 
-		SET server_name = $2`,
+		SET server_name = COALESCE(NULLIF(EXCLUDED.server_name, ''), servers.server_name),
+			left_at = NULL`,
 		serverID, serverName,
 	)
 	return err
 }
 
-// ServerExists checks if a server exists in the database
-func (d *Database) ServerExists(serverID string) (bool, error) {
+// MarkServerLeft records that the bot has left a server
+func (d *Database) MarkServerLeft(ctx context.Context, serverID string) error {
+	ctx, done := d.query(ctx, "mark_server_left")
+	defer done()
+
+	_, err := d.db.ExecContext(ctx,
+		`UPDATE servers 
+		SET left_at = CURRENT_TIMESTAMP 
+		WHERE server_id = $1 AND left_at IS NULL`,
+		serverID,
+	)
+	return err
+}
+
+// ReconcileServers registers all given servers and marks every other active server as left.
+// Servers with an empty name keep their stored name. It returns the number of servers marked as left.
+// The transaction is bounded by a longer timeout than single queries, as it touches every server.
+func (d *Database) ReconcileServers(ctx context.Context, servers []Server) (int64, error) {
+	ctx, done := d.queryWithTimeout(ctx, "reconcile_servers", max(d.queryTimeout, reconcileTimeout))
+	defer done()
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids := make([]string, len(servers))
+	for i, server := range servers {
+		ids[i] = server.ID
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO servers (server_id, server_name) 
+			VALUES ($1, $2) 
+			ON CONFLICT (server_id) DO UPDATE 
+			SET server_name = COALESCE(NULLIF(EXCLUDED.server_name, ''), servers.server_name),
+				left_at = NULL`,
+			server.ID, server.Name,
+		); err != nil {
+			return 0, fmt.Errorf("failed to register server %s: %w", server.ID, err)
+		}
+	}
+
+	markLeft, arg := `UPDATE servers 
+		SET left_at = CURRENT_TIMESTAMP 
+		WHERE left_at IS NULL AND server_id <> ALL($1)`, any(ids)
+	if d.driver == DriverSQLite {
+		// SQLite has no arrays, the IDs are passed as a JSON array instead
+		encoded, err := json.Marshal(ids)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode server IDs: %w", err)
+		}
+		markLeft, arg = `UPDATE servers 
+		SET left_at = CURRENT_TIMESTAMP 
+		WHERE left_at IS NULL AND server_id NOT IN (SELECT value FROM json_each($1))`, string(encoded)
+	}
+
+	res, err := tx.ExecContext(ctx, markLeft, arg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark departed servers: %w", err)
+	}
+	left, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count departed servers: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return left, nil
+}
+
+// ServerExists checks if a server exists in the database and the bot has not left it
+func (d *Database) ServerExists(ctx context.Context, serverID string) (bool, error) {
+	ctx, done := d.query(ctx, "server_exists")
+	defer done()
+
 	var exists bool
-	err := d.db.QueryRow("SELECT EXISTS(SELECT 1 FROM servers WHERE server_id = $1)", serverID).Scan(&exists)
+	err := d.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM servers WHERE server_id = $1 AND left_at IS NULL)", serverID).Scan(&exists)
 	return exists, err
 }
@@ -0,0 +1,16 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/zokiio/mukabi/service/bot/db/dbtest"
+)
+
+// TestPostgresStore is skipped unless a test database is configured, see dbtest.PostgresEnvPrefix
+func TestPostgresStore(t *testing.T) {
+	dbtest.Run(t, dbtest.Postgres)
+}
+
+func TestSQLiteStore(t *testing.T) {
+	dbtest.Run(t, dbtest.SQLite)
+}
@@ -0,0 +1,444 @@
+// Package dbtest provides a conformance suite verifying that db.Store implementations behave
+// like the database, so tests can rely on the in-memory store.
+//
+// Run the suite from a test of each implementation:
+//
+//	func TestMemoryStore(t *testing.T) {
+//		dbtest.Run(t, func(t *testing.T) db.Store { return memory.New() })
+//	}
+//
+//	func TestPostgresStore(t *testing.T) {
+//		dbtest.Run(t, dbtest.Postgres)
+//	}
+//
+//	func TestSQLiteStore(t *testing.T) {
+//		dbtest.Run(t, dbtest.SQLite)
+//	}
+package dbtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/zokiio/mukabi/internal/wow"
+	"github.com/zokiio/mukabi/service/bot"
+	"github.com/zokiio/mukabi/service/bot/db"
+)
+
+// PostgresEnvPrefix prefixes the environment variables configuring the Postgres test database,
+// e.g. MUKABI_TEST_DATABASE_HOST. The suite modifies all data, so use a dedicated database.
+const PostgresEnvPrefix = "MUKABI_TEST_DATABASE_"
+
+// Postgres opens the Postgres test database configured by MUKABI_TEST_DATABASE_* environment
+// variables and applies the schema. The test is skipped if the database is not configured.
+func Postgres(t *testing.T) db.Store {
+	t.Helper()
+
+	database, err := bot.OpenDatabase(bot.Config{Database: PostgresConfig(t)})
+	if err != nil {
+		t.Fatalf("failed to open Postgres test database: %s", err)
+	}
+	return database
+}
+
+// PostgresConfig returns the Postgres test database settings from the MUKABI_TEST_DATABASE_*
+// environment variables. MUKABI_TEST_DATABASE_URL replaces the discrete settings.
+// The test is skipped if neither a URL nor a host is configured.
+func PostgresConfig(t *testing.T) db.Config {
+	t.Helper()
+
+	dsn := os.Getenv(PostgresEnvPrefix + "URL")
+	host := os.Getenv(PostgresEnvPrefix + "HOST")
+	if dsn == "" && host == "" {
+		t.Skip("Postgres test database not configured, set " + PostgresEnvPrefix + "URL or " + PostgresEnvPrefix + "HOST")
+	}
+	port, _ := strconv.Atoi(os.Getenv(PostgresEnvPrefix + "PORT"))
+	if port == 0 {
+		port = 5432
+	}
+
+	cfg := db.Config{
+		Driver:         db.DriverPostgres,
+		URL:            dsn,
+		Host:           host,
+		Port:           port,
+		Username:       os.Getenv(PostgresEnvPrefix + "USERNAME"),
+		Password:       os.Getenv(PostgresEnvPrefix + "PASSWORD"),
+		Database:       os.Getenv(PostgresEnvPrefix + "DATABASE"),
+		SSLMode:        os.Getenv(PostgresEnvPrefix + "SSL_MODE"),
+		ConnectTimeout: 5 * time.Second,
+	}
+	if cfg.SSLMode == "" {
+		cfg.SSLMode = "disable"
+	}
+	return cfg
+}
+
+// SQLite opens a new SQLite database in a temporary directory of the test and applies the schema
+func SQLite(t *testing.T) db.Store {
+	t.Helper()
+
+	database, err := bot.OpenDatabase(bot.Config{Database: db.Config{
+		Driver:         db.DriverSQLite,
+		Database:       filepath.Join(t.TempDir(), "mukabi.db"),
+		ConnectTimeout: 5 * time.Second,
+	}})
+	if err != nil {
+		t.Fatalf("failed to open SQLite test database: %s", err)
+	}
+	return database
+}
+
+// Run runs the conformance suite against stores created by open.
+// Every subtest gets its own store, which is closed when the subtest ends.
+func Run(t *testing.T, open func(t *testing.T) db.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s db.Store, ids *ids)
+	}{
+		{"Ping", testPing},
+		{"RegisterServer", testRegisterServer},
+		{"ReconcileServers", testReconcileServers},
+		{"RegisterCharacter", testRegisterCharacter},
+		{"GetCharacter", testGetCharacter},
+		{"CharacterIdentity", testCharacterIdentity},
+		{"SoftDeleteMember", testSoftDeleteMember},
+		{"PurgeExpired", testPurgeExpired},
+		{"DeleteUserData", testDeleteUserData},
+		{"Timeout", testTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := open(t)
+			t.Cleanup(func() {
+				if err := s.Close(); err != nil {
+					t.Errorf("failed to close store: %s", err)
+				}
+			})
+			tt.fn(t, s, newIDs())
+		})
+	}
+}
+
+// ids generates IDs unique to a test, so runs against a shared database do not collide
+type ids struct {
+	prefix string
+	n      int
+}
+
+func newIDs() *ids {
+	return &ids{prefix: strconv.FormatUint(rand.Uint64(), 36)}
+}
+
+// next returns a new unique ID
+func (i *ids) next() string {
+	i.n++
+	return fmt.Sprintf("%s-%d", i.prefix, i.n)
+}
+
+func testPing(t *testing.T, s db.Store, _ *ids) {
+	if err := s.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() = %s, want nil", err)
+	}
+}
+
+func testRegisterServer(t *testing.T, s db.Store, ids *ids) {
+	ctx := context.Background()
+	server := ids.next()
+
+	assertServerExists(t, s, server, false)
+	must(t, s.RegisterServer(ctx, server, "Guild"))
+	assertServerExists(t, s, server, true)
+
+	// Registering again is idempotent
+	must(t, s.RegisterServer(ctx, server, ""))
+	assertServerExists(t, s, server, true)
+
+	must(t, s.MarkServerLeft(ctx, server))
+	assertServerExists(t, s, server, false)
+
+	// Rejoining clears the left marker
+	must(t, s.RegisterServer(ctx, server, "Guild"))
+	assertServerExists(t, s, server, true)
+}
+
+func testReconcileServers(t *testing.T, s db.Store, ids *ids) {
+	ctx := context.Background()
+	a, b, c, d := ids.next(), ids.next(), ids.next(), ids.next()
+
+	// The first reconcile also marks servers of other tests as left, so its count is not checked
+	_, err := s.ReconcileServers(ctx, []db.Server{{ID: a, Name: "A"}, {ID: b, Name: "B"}, {ID: c, Name: "C"}})
+	must(t, err)
+
+	left, err := s.ReconcileServers(ctx, []db.Server{{ID: a, Name: "A"}, {ID: d}})
+	must(t, err)
+	if left != 2 {
+		t.Errorf("ReconcileServers() left = %d, want 2", left)
+	}
+
+	assertServerExists(t, s, a, true)
+	assertServerExists(t, s, b, false)
+	assertServerExists(t, s, c, false)
+	assertServerExists(t, s, d, true)
+}
+
+func testRegisterCharacter(t *testing.T, s db.Store, ids *ids) {
+	ctx := context.Background()
+	server, user := ids.next(), ids.next()
+	must(t, s.RegisterServer(ctx, server, "Guild"))
+
+	character := db.WoWCharacter{CharacterName: "Zoki", Region: "EU", Realm: "twisting-nether"}
+	must(t, s.WoWRegisterCharacter(ctx, server, user, character))
+
+	err := s.WoWRegisterCharacter(ctx, server, user, character)
+	if !errors.Is(err, db.ErrCharacterExists) {
+		t.Errorf("registering twice = %v, want %v", err, db.ErrCharacterExists)
+	}
+
+	err = s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Other", Region: "xx", Realm: "realm"})
+	if !errors.Is(err, wow.ErrUnknownRegion) {
+		t.Errorf("registering with unknown region = %v, want %v", err, wow.ErrUnknownRegion)
+	}
+
+	if err := s.WoWRegisterCharacter(ctx, ids.next(), user, db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "realm"}); err == nil {
+		t.Error("registering for an unknown server succeeded, want error")
+	}
+
+	// The same character can be registered by another user
+	must(t, s.WoWRegisterCharacter(ctx, server, ids.next(), character))
+
+	// The region is stored normalized
+	got, err := s.WoWGetCharacter(ctx, server, user, character)
+	must(t, err)
+	want := db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "twisting-nether"}
+	if got != want {
+		t.Errorf("WoWGetCharacter() = %+v, want %+v", got, want)
+	}
+}
+
+func testCharacterIdentity(t *testing.T, s db.Store, ids *ids) {
+	ctx := context.Background()
+	server, user := ids.next(), ids.next()
+	must(t, s.RegisterServer(ctx, server, "Guild"))
+
+	must(t, s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "twisting-nether"}))
+
+	// Names differing only in case are the same character
+	err := s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "zoki", Region: "eu", Realm: "twisting-nether"})
+	if !errors.Is(err, db.ErrCharacterExists) {
+		t.Errorf("registering with different case = %v, want %v", err, db.ErrCharacterExists)
+	}
+
+	// Realm names typed by users are looked up by their slug
+	got, err := s.WoWGetCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "ZOKI", Region: "EU", Realm: "Twisting Nether"})
+	must(t, err)
+	want := db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "twisting-nether"}
+	if got != want {
+		t.Errorf("WoWGetCharacter(ZOKI) = %+v, want %+v", got, want)
+	}
+
+	// Characters with the same name on other realms or regions are different characters
+	must(t, s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "aggra-portugues"}))
+	must(t, s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Zoki", Region: "us", Realm: "twisting-nether"}))
+	got, err = s.WoWGetCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "zoki", Region: "eu", Realm: "Aggra (Português)"})
+	must(t, err)
+	want = db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "aggra-portugues"}
+	if got != want {
+		t.Errorf("WoWGetCharacter(Aggra (Português)) = %+v, want %+v", got, want)
+	}
+
+	// Slugs are stored as Raider.IO returns them, even if slugging the realm name would differ
+	must(t, s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "aggra-português"}))
+	got, err = s.WoWGetCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "aggra-português"})
+	must(t, err)
+	want = db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "aggra-português"}
+	if got != want {
+		t.Errorf("WoWGetCharacter(aggra-português) = %+v, want %+v", got, want)
+	}
+	if _, err := s.WoWGetCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "ravencrest"}); !errors.Is(err, db.ErrCharacterNotFound) {
+		t.Errorf("WoWGetCharacter() on another realm = %v, want %v", err, db.ErrCharacterNotFound)
+	}
+	if _, err := s.WoWGetCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Zoki", Region: "xx", Realm: "twisting-nether"}); !errors.Is(err, db.ErrCharacterNotFound) {
+		t.Errorf("WoWGetCharacter() with unknown region = %v, want %v", err, db.ErrCharacterNotFound)
+	}
+	assertCharacters(t, s, server, user, "Zoki", "Zoki", "Zoki", "Zoki")
+
+	// Restoring a soft-deleted character takes over the new spelling
+	_, err = s.SoftDeleteMember(ctx, server, user)
+	must(t, err)
+	must(t, s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "ZOKI", Region: "eu", Realm: "twisting-nether"}))
+	assertCharacters(t, s, server, user, "ZOKI")
+}
+
+func testGetCharacter(t *testing.T, s db.Store, ids *ids) {
+	ctx := context.Background()
+	server, user, other := ids.next(), ids.next(), ids.next()
+	must(t, s.RegisterServer(ctx, server, "Guild"))
+
+	assertHasCharacter(t, s, server, user, false)
+	assertCharacters(t, s, server, user)
+
+	must(t, s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "twisting-nether"}))
+	must(t, s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Mukabi", Region: "us", Realm: "area-52"}))
+	must(t, s.WoWRegisterCharacter(ctx, server, other, db.WoWCharacter{CharacterName: "Other", Region: "eu", Realm: "ravencrest"}))
+
+	assertHasCharacter(t, s, server, user, true)
+	assertCharacters(t, s, server, user, "Mukabi", "Zoki")
+	assertCharacters(t, s, server, other, "Other")
+
+	_, err := s.WoWGetCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Other", Region: "eu", Realm: "ravencrest"})
+	if !errors.Is(err, db.ErrCharacterNotFound) {
+		t.Errorf("WoWGetCharacter() of another user = %v, want %v", err, db.ErrCharacterNotFound)
+	}
+}
+
+func testSoftDeleteMember(t *testing.T, s db.Store, ids *ids) {
+	ctx := context.Background()
+	server, user := ids.next(), ids.next()
+	must(t, s.RegisterServer(ctx, server, "Guild"))
+	character := db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "twisting-nether"}
+	must(t, s.WoWRegisterCharacter(ctx, server, user, character))
+
+	deleted, err := s.SoftDeleteMember(ctx, server, user)
+	must(t, err)
+	if deleted != 1 {
+		t.Errorf("SoftDeleteMember() = %d, want 1", deleted)
+	}
+	assertHasCharacter(t, s, server, user, false)
+	assertCharacters(t, s, server, user)
+	if _, err := s.WoWGetCharacter(ctx, server, user, character); !errors.Is(err, db.ErrCharacterNotFound) {
+		t.Errorf("WoWGetCharacter() of deleted character = %v, want %v", err, db.ErrCharacterNotFound)
+	}
+
+	// Registering a soft-deleted character restores it
+	must(t, s.WoWRegisterCharacter(ctx, server, user, character))
+	assertCharacters(t, s, server, user, "Zoki")
+}
+
+func testPurgeExpired(t *testing.T, s db.Store, ids *ids) {
+	ctx := context.Background()
+	server, departed, user := ids.next(), ids.next(), ids.next()
+	must(t, s.RegisterServer(ctx, server, "Guild"))
+	must(t, s.RegisterServer(ctx, departed, "Departed"))
+	must(t, s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Deleted", Region: "eu", Realm: "realm"}))
+	must(t, s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Kept", Region: "eu", Realm: "realm"}))
+	must(t, s.WoWRegisterCharacter(ctx, departed, user, db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "realm"}))
+
+	_, err := s.SoftDeleteMember(ctx, server, user)
+	must(t, err)
+	must(t, s.WoWRegisterCharacter(ctx, server, user, db.WoWCharacter{CharacterName: "Kept", Region: "eu", Realm: "realm"}))
+	must(t, s.MarkServerLeft(ctx, departed))
+
+	// Nothing left or deleted after the cutoff is purged
+	_, err = s.PurgeExpired(ctx, time.Now().Add(-time.Hour))
+	must(t, err)
+	must(t, s.RegisterServer(ctx, departed, "Departed"))
+	assertCharacters(t, s, departed, user, "Zoki")
+	must(t, s.MarkServerLeft(ctx, departed))
+
+	result, err := s.PurgeExpired(ctx, time.Now().Add(time.Hour))
+	must(t, err)
+	if result.Servers < 1 || result.Characters < 1 {
+		t.Errorf("PurgeExpired() = %+v, want at least one server and character", result)
+	}
+	assertCharacters(t, s, server, user, "Kept")
+
+	// Characters of purged servers are gone when the bot rejoins
+	must(t, s.RegisterServer(ctx, departed, "Departed"))
+	assertCharacters(t, s, departed, user)
+}
+
+func testDeleteUserData(t *testing.T, s db.Store, ids *ids) {
+	ctx := context.Background()
+	a, b, user, other := ids.next(), ids.next(), ids.next(), ids.next()
+	must(t, s.RegisterServer(ctx, a, "A"))
+	must(t, s.RegisterServer(ctx, b, "B"))
+	must(t, s.WoWRegisterCharacter(ctx, a, user, db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "realm"}))
+	must(t, s.WoWRegisterCharacter(ctx, b, user, db.WoWCharacter{CharacterName: "Zoki", Region: "eu", Realm: "realm"}))
+	must(t, s.WoWRegisterCharacter(ctx, a, other, db.WoWCharacter{CharacterName: "Other", Region: "eu", Realm: "realm"}))
+
+	deleted, err := s.DeleteUserData(ctx, user)
+	must(t, err)
+	if deleted != 2 {
+		t.Errorf("DeleteUserData() = %d, want 2", deleted)
+	}
+	assertCharacters(t, s, a, user)
+	assertCharacters(t, s, b, user)
+	assertCharacters(t, s, a, other, "Other")
+}
+
+func testTimeout(t *testing.T, s db.Store, ids *ids) {
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	if _, err := s.ServerExists(ctx, ids.next()); !db.IsTimeout(err) {
+		t.Errorf("ServerExists() with expired deadline = %v, want timeout", err)
+	}
+	if err := s.RegisterServer(ctx, ids.next(), "Guild"); !db.IsTimeout(err) {
+		t.Errorf("RegisterServer() with expired deadline = %v, want timeout", err)
+	}
+
+	// A cancelled context is not a timeout
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.WoWGetCharacters(ctx, ids.next(), ids.next()); err == nil || db.IsTimeout(err) {
+		t.Errorf("WoWGetCharacters() with cancelled context = %v, want cancellation error", err)
+	}
+}
+
+// must fails the test immediately on error
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertServerExists checks whether a server is registered and active
+func assertServerExists(t *testing.T, s db.Store, serverID string, want bool) {
+	t.Helper()
+	ctx := context.Background()
+	exists, err := s.ServerExists(ctx, serverID)
+	must(t, err)
+	if exists != want {
+		t.Errorf("ServerExists(%s) = %t, want %t", serverID, exists, want)
+	}
+}
+
+// assertHasCharacter checks whether a user has registered characters
+func assertHasCharacter(t *testing.T, s db.Store, serverID, discordID string, want bool) {
+	t.Helper()
+	ctx := context.Background()
+	has, err := s.WoWHasRegisteredCharacter(ctx, serverID, discordID)
+	must(t, err)
+	if has != want {
+		t.Errorf("WoWHasRegisteredCharacter() = %t, want %t", has, want)
+	}
+}
+
+// assertCharacters checks the names of the characters of a user, in any order
+func assertCharacters(t *testing.T, s db.Store, serverID, discordID string, want ...string) {
+	t.Helper()
+	ctx := context.Background()
+	characters, err := s.WoWGetCharacters(ctx, serverID, discordID)
+	must(t, err)
+
+	names := make([]string, len(characters))
+	for i, c := range characters {
+		names[i] = c.CharacterName
+	}
+	slices.Sort(names)
+	slices.Sort(want)
+	if !slices.Equal(names, want) {
+		t.Errorf("WoWGetCharacters() = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,336 @@
+// Package db provides export and import of database contents
+package db
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/zokiio/mukabi/internal/metrics"
+	"github.com/zokiio/mukabi/internal/wow"
+)
+
+// ArchiveVersion is the current version of the export archive format
+const ArchiveVersion = 1
+
+// Format is the encoding of an export archive
+type Format string
+
+// Supported archive formats
+const (
+	FormatJSON   Format = "json"   // A single JSON document
+	FormatNDJSON Format = "ndjson" // A header line followed by one record per line
+)
+
+// ParseFormat returns the archive format for a name
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatJSON, FormatNDJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported archive format %q", name)
+	}
+}
+
+// Archive is a portable snapshot of all bot data
+type Archive struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Servers    []ServerRecord    `json:"servers"`
+	Characters []CharacterRecord `json:"characters"`
+}
+
+// ServerRecord is an exported row of the servers table
+type ServerRecord struct {
+	ServerID   string     `json:"server_id" db:"server_id"`
+	ServerName string     `json:"server_name" db:"server_name"`
+	LeftAt     *time.Time `json:"left_at,omitempty" db:"left_at"`
+}
+
+// CharacterRecord is an exported row of the wow_characters table
+type CharacterRecord struct {
+	DiscordID     string     `json:"discord_id" db:"discord_id"`
+	ServerID      string     `json:"server_id" db:"server_id"`
+	CharacterName string     `json:"character_name" db:"character_name"`
+	CharacterKey  string     `json:"-" db:"character_key"` // Derived from the name on import
+	Region        string     `json:"region" db:"region"`
+	Realm         string     `json:"realm" db:"realm"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// Summary counts the rows read or written by an export or import
+type Summary struct {
+	Servers    int
+	Characters int
+}
+
+// String returns a human readable summary
+func (s Summary) String() string {
+	return fmt.Sprintf("%d servers, %d characters", s.Servers, s.Characters)
+}
+
+// ndjsonRecord is a single line of an NDJSON archive
+type ndjsonRecord struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// NDJSON record types
+const (
+	recordHeader    = "header"
+	recordServer    = "server"
+	recordCharacter = "character"
+)
+
+// ndjsonHeader is the data of the first line of an NDJSON archive
+type ndjsonHeader struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// Export writes all servers and characters as an archive in the given format.
+// The query timeout does not apply, so large exports are only bounded by ctx.
+func (d *Database) Export(ctx context.Context, w io.Writer, format Format) (Summary, error) {
+	defer metrics.ObserveQuery("export")()
+
+	archive := Archive{
+		Version:    ArchiveVersion,
+		ExportedAt: time.Now().UTC(),
+	}
+
+	if err := d.db.SelectContext(ctx, &archive.Servers,
+		`SELECT server_id, COALESCE(server_name, '') AS server_name, left_at 
+		FROM servers 
+		ORDER BY server_id`,
+	); err != nil {
+		return Summary{}, fmt.Errorf("failed to export servers: %w", err)
+	}
+
+	if err := d.db.SelectContext(ctx, &archive.Characters,
+		`SELECT discord_id, server_id, character_name, COALESCE(region, '') AS region, COALESCE(realm, '') AS realm, deleted_at 
+		FROM wow_characters 
+		ORDER BY server_id, discord_id, character_key`,
+	); err != nil {
+		return Summary{}, fmt.Errorf("failed to export characters: %w", err)
+	}
+
+	if err := WriteArchive(w, archive, format); err != nil {
+		return Summary{}, err
+	}
+	return archive.Summary(), nil
+}
+
+// Import reads an archive in any supported format, validates it and upserts its contents
+// in a single transaction. Importing the same archive twice leaves the database unchanged.
+// The query timeout does not apply, so large imports are only bounded by ctx.
+func (d *Database) Import(ctx context.Context, r io.Reader) (Summary, error) {
+	defer metrics.ObserveQuery("import")()
+
+	archive, err := ReadArchive(r)
+	if err != nil {
+		return Summary{}, err
+	}
+	if err := archive.Validate(); err != nil {
+		return Summary{}, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var summary Summary
+	for _, server := range archive.Servers {
+		server.LeftAt = utc(server.LeftAt)
+		if _, err := tx.NamedExecContext(ctx,
+			`INSERT INTO servers (server_id, server_name, left_at) 
+			VALUES (:server_id, :server_name, :left_at)
+			ON CONFLICT (server_id) DO UPDATE 
+			SET server_name = EXCLUDED.server_name, left_at = EXCLUDED.left_at`,
+			server,
+		); err != nil {
+			return Summary{}, fmt.Errorf("failed to import server %s: %w", server.ServerID, err)
+		}
+		summary.Servers++
+	}
+
+	// Names on the same realm differing only in case are the same character, the last record wins
+	for _, character := range archive.Characters {
+		// Store the canonical region code, Validate already rejected unknown regions
+		region, _ := wow.ParseRegion(character.Region)
+		character.Region = region.Code
+		character.CharacterKey = wow.CharacterKey(character.CharacterName)
+		character.DeletedAt = utc(character.DeletedAt)
+		if _, err := tx.NamedExecContext(ctx,
+			`INSERT INTO wow_characters (discord_id, server_id, character_name, character_key, region, realm, deleted_at) 
+			VALUES (:discord_id, :server_id, :character_name, :character_key, :region, :realm, :deleted_at)
+			ON CONFLICT (discord_id, server_id, region, realm, character_key) DO UPDATE 
+			SET character_name = EXCLUDED.character_name, deleted_at = EXCLUDED.deleted_at`,
+			character,
+		); err != nil {
+			return Summary{}, fmt.Errorf("failed to import character %s: %w", character.CharacterName, err)
+		}
+		summary.Characters++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Summary{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return summary, nil
+}
+
+// Summary returns the number of records in the archive
+func (a Archive) Summary() Summary {
+	return Summary{Servers: len(a.Servers), Characters: len(a.Characters)}
+}
+
+// Validate checks the archive version and that every record is complete and consistent
+func (a Archive) Validate() error {
+	if a.Version != ArchiveVersion {
+		return fmt.Errorf("unsupported archive version %d", a.Version)
+	}
+
+	var errs []error
+	servers := make(map[string]bool, len(a.Servers))
+	for i, server := range a.Servers {
+		if _, err := snowflake.Parse(server.ServerID); err != nil {
+			errs = append(errs, fmt.Errorf("server %d: invalid server_id %q", i, server.ServerID))
+			continue
+		}
+		if servers[server.ServerID] {
+			errs = append(errs, fmt.Errorf("server %d: duplicate server_id %s", i, server.ServerID))
+		}
+		servers[server.ServerID] = true
+	}
+
+	for i, character := range a.Characters {
+		switch {
+		case !servers[character.ServerID]:
+			errs = append(errs, fmt.Errorf("character %d: unknown server_id %q", i, character.ServerID))
+		case character.CharacterName == "":
+			errs = append(errs, fmt.Errorf("character %d: missing character_name", i))
+		case character.Realm == "":
+			errs = append(errs, fmt.Errorf("character %d: missing realm", i))
+		}
+		if _, err := snowflake.Parse(character.DiscordID); err != nil {
+			errs = append(errs, fmt.Errorf("character %d: invalid discord_id %q", i, character.DiscordID))
+		}
+		if _, err := wow.ParseRegion(character.Region); err != nil {
+			errs = append(errs, fmt.Errorf("character %d: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WriteArchive encodes an archive in the given format
+func WriteArchive(w io.Writer, archive Archive, format Format) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(archive); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+		return nil
+	case FormatNDJSON:
+		return writeNDJSON(w, archive)
+	default:
+		return fmt.Errorf("unsupported archive format %q", format)
+	}
+}
+
+// writeNDJSON writes a header line followed by one line per server and character
+func writeNDJSON(w io.Writer, archive Archive) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	write := func(recordType string, data any) error {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		return enc.Encode(ndjsonRecord{Type: recordType, Data: raw})
+	}
+
+	if err := write(recordHeader, ndjsonHeader{Version: archive.Version, ExportedAt: archive.ExportedAt}); err != nil {
+		return fmt.Errorf("failed to write archive header: %w", err)
+	}
+	for _, server := range archive.Servers {
+		if err := write(recordServer, server); err != nil {
+			return fmt.Errorf("failed to write server %s: %w", server.ServerID, err)
+		}
+	}
+	for _, character := range archive.Characters {
+		if err := write(recordCharacter, character); err != nil {
+			return fmt.Errorf("failed to write character %s: %w", character.CharacterName, err)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ReadArchive decodes an archive, detecting whether it is a JSON document or NDJSON
+func ReadArchive(r io.Reader) (Archive, error) {
+	dec := json.NewDecoder(r)
+
+	var first json.RawMessage
+	if err := dec.Decode(&first); err != nil {
+		return Archive{}, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	var record ndjsonRecord
+	if err := json.Unmarshal(first, &record); err == nil && record.Type == recordHeader {
+		return readNDJSON(dec, record)
+	}
+
+	var archive Archive
+	if err := json.Unmarshal(first, &archive); err != nil {
+		return Archive{}, fmt.Errorf("failed to decode archive: %w", err)
+	}
+	return archive, nil
+}
+
+// readNDJSON decodes the remaining records of an NDJSON archive after its header
+func readNDJSON(dec *json.Decoder, headerRecord ndjsonRecord) (Archive, error) {
+	var header ndjsonHeader
+	if err := json.Unmarshal(headerRecord.Data, &header); err != nil {
+		return Archive{}, fmt.Errorf("failed to decode archive header: %w", err)
+	}
+	archive := Archive{Version: header.Version, ExportedAt: header.ExportedAt}
+
+	for line := 2; ; line++ {
+		var record ndjsonRecord
+		if err := dec.Decode(&record); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return Archive{}, fmt.Errorf("failed to read record %d: %w", line, err)
+		}
+
+		switch record.Type {
+		case recordServer:
+			var server ServerRecord
+			if err := json.Unmarshal(record.Data, &server); err != nil {
+				return Archive{}, fmt.Errorf("failed to decode server record %d: %w", line, err)
+			}
+			archive.Servers = append(archive.Servers, server)
+		case recordCharacter:
+			var character CharacterRecord
+			if err := json.Unmarshal(record.Data, &character); err != nil {
+				return Archive{}, fmt.Errorf("failed to decode character record %d: %w", line, err)
+			}
+			archive.Characters = append(archive.Characters, character)
+		default:
+			return Archive{}, fmt.Errorf("unknown record type %q on record %d", record.Type, line)
+		}
+	}
+
+	return archive, nil
+}
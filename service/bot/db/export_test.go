@@ -0,0 +1,249 @@
+package db_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zokiio/mukabi/service/bot/db"
+	"github.com/zokiio/mukabi/service/bot/db/dbtest"
+)
+
+// testArchive returns a valid archive with a departed server and a deleted character
+func testArchive() db.Archive {
+	leftAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	deletedAt := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	return db.Archive{
+		Version:    db.ArchiveVersion,
+		ExportedAt: time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC),
+		Servers: []db.ServerRecord{
+			{ServerID: "100", ServerName: "Guild"},
+			{ServerID: "200", ServerName: "Old Guild", LeftAt: &leftAt},
+		},
+		Characters: []db.CharacterRecord{
+			{DiscordID: "1", ServerID: "100", CharacterName: "Zoki", Region: "eu", Realm: "twisting-nether"},
+			{DiscordID: "1", ServerID: "200", CharacterName: "Zokí", Region: "us", Realm: "area-52", DeletedAt: &deletedAt},
+		},
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	for _, format := range []db.Format{db.FormatJSON, db.FormatNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			want := testArchive()
+
+			var buf bytes.Buffer
+			if err := db.WriteArchive(&buf, want, format); err != nil {
+				t.Fatalf("WriteArchive() error = %s", err)
+			}
+			got, err := db.ReadArchive(&buf)
+			if err != nil {
+				t.Fatalf("ReadArchive() error = %s", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ReadArchive() = %+v, want %+v", got, want)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("Validate() error = %s", err)
+			}
+		})
+	}
+}
+
+func TestSQLiteExportImport(t *testing.T) {
+	ctx := context.Background()
+	database := dbtest.SQLite(t).(*db.Database)
+	t.Cleanup(func() { database.Close() })
+
+	// Timestamps in other zones are stored in UTC, so they still compare correctly
+	archive := testArchive()
+	zone := time.FixedZone("CEST", 2*60*60)
+	leftAt := archive.Servers[1].LeftAt.In(zone)
+	archive.Servers[1].LeftAt = &leftAt
+
+	var in bytes.Buffer
+	if err := db.WriteArchive(&in, archive, db.FormatNDJSON); err != nil {
+		t.Fatalf("WriteArchive() error = %s", err)
+	}
+	if _, err := database.Import(ctx, &in); err != nil {
+		t.Fatalf("Import() error = %s", err)
+	}
+
+	var out bytes.Buffer
+	summary, err := database.Export(ctx, &out, db.FormatJSON)
+	if err != nil {
+		t.Fatalf("Export() error = %s", err)
+	}
+	if summary != (db.Summary{Servers: 2, Characters: 2}) {
+		t.Errorf("Export() = %+v, want 2 servers and 2 characters", summary)
+	}
+	got, err := db.ReadArchive(&out)
+	if err != nil {
+		t.Fatalf("ReadArchive() error = %s", err)
+	}
+	want := testArchive()
+	if !reflect.DeepEqual(got.Servers, want.Servers) {
+		t.Errorf("exported servers = %+v, want %+v", got.Servers, want.Servers)
+	}
+	if !reflect.DeepEqual(got.Characters, want.Characters) {
+		t.Errorf("exported characters = %+v, want %+v", got.Characters, want.Characters)
+	}
+
+	// The departed server left before the cutoff, expressed in local time
+	result, err := database.PurgeExpired(ctx, want.Servers[1].LeftAt.Add(time.Minute).Local())
+	if err != nil {
+		t.Fatalf("PurgeExpired() error = %s", err)
+	}
+	if result.Servers != 1 {
+		t.Errorf("PurgeExpired() = %+v, want 1 server", result)
+	}
+}
+
+func TestReadArchiveFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    db.Summary
+		wantErr string
+	}{
+		{
+			name:  "json",
+			input: `{"version": 1, "servers": [{"server_id": "100"}], "characters": []}`,
+			want:  db.Summary{Servers: 1},
+		},
+		{
+			name: "ndjson",
+			input: `{"type": "header", "data": {"version": 1}}
+{"type": "server", "data": {"server_id": "100"}}
+{"type": "character", "data": {"discord_id": "1", "server_id": "100", "character_name": "Zoki", "region": "eu", "realm": "twisting-nether"}}
+`,
+			want: db.Summary{Servers: 1, Characters: 1},
+		},
+		{
+			name:  "json with a type field",
+			input: `{"type": "server", "version": 1, "servers": []}`,
+		},
+		{
+			name:    "empty",
+			input:   "",
+			wantErr: "failed to read archive",
+		},
+		{
+			name:    "not json",
+			input:   "servers,characters",
+			wantErr: "failed to read archive",
+		},
+		{
+			name:    "unknown record type",
+			input:   `{"type": "header", "data": {"version": 1}}` + "\n" + `{"type": "guild", "data": {}}`,
+			wantErr: `unknown record type "guild" on record 2`,
+		},
+		{
+			name:    "broken record",
+			input:   `{"type": "header", "data": {"version": 1}}` + "\n" + `{"type": "server", "data": {"server_id": 100}}`,
+			wantErr: "failed to decode server record 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive, err := db.ReadArchive(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ReadArchive() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadArchive() error = %s", err)
+			}
+			if archive.Version != db.ArchiveVersion || archive.Summary() != tt.want {
+				t.Errorf("ReadArchive() = version %d with %s, want version %d with %s", archive.Version, archive.Summary(), db.ArchiveVersion, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteArchiveUnknownFormat(t *testing.T) {
+	if err := db.WriteArchive(&bytes.Buffer{}, testArchive(), "csv"); err == nil {
+		t.Error("WriteArchive() with an unknown format succeeded, want error")
+	}
+	if _, err := db.ParseFormat("csv"); err == nil {
+		t.Error("ParseFormat(csv) succeeded, want error")
+	}
+	if format, err := db.ParseFormat("NDJSON"); err != nil || format != db.FormatNDJSON {
+		t.Errorf("ParseFormat(NDJSON) = %q, %v, want ndjson", format, err)
+	}
+}
+
+func TestArchiveValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*db.Archive)
+		want   string
+	}{
+		{
+			name:   "unknown version",
+			modify: func(a *db.Archive) { a.Version = 2 },
+			want:   "unsupported archive version 2",
+		},
+		{
+			name:   "duplicate server",
+			modify: func(a *db.Archive) { a.Servers = append(a.Servers, db.ServerRecord{ServerID: "100"}) },
+			want:   "server 2: duplicate server_id 100",
+		},
+		{
+			name:   "invalid server",
+			modify: func(a *db.Archive) { a.Servers[1].ServerID = "guild" },
+			want:   `server 1: invalid server_id "guild"`,
+		},
+		{
+			name:   "missing server",
+			modify: func(a *db.Archive) { a.Characters[1].ServerID = "300" },
+			want:   `character 1: unknown server_id "300"`,
+		},
+		{
+			name:   "missing name",
+			modify: func(a *db.Archive) { a.Characters[0].CharacterName = "" },
+			want:   "character 0: missing character_name",
+		},
+		{
+			name:   "missing realm",
+			modify: func(a *db.Archive) { a.Characters[0].Realm = "" },
+			want:   "character 0: missing realm",
+		},
+		{
+			name:   "invalid discord id",
+			modify: func(a *db.Archive) { a.Characters[0].DiscordID = "zoki" },
+			want:   `character 0: invalid discord_id "zoki"`,
+		},
+		{
+			name:   "unknown region",
+			modify: func(a *db.Archive) { a.Characters[0].Region = "xx" },
+			want:   `character 0: unknown region: "xx"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := testArchive()
+			tt.modify(&archive)
+			err := archive.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchiveValidateReportsAllErrors(t *testing.T) {
+	archive := testArchive()
+	archive.Characters[0].Region = "xx"
+	archive.Characters[1].ServerID = "300"
+
+	err := archive.Validate()
+	if err == nil || !strings.Contains(err.Error(), "character 0") || !strings.Contains(err.Error(), "character 1") {
+		t.Errorf("Validate() error = %v, want errors of both characters", err)
+	}
+}
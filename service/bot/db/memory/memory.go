@@ -0,0 +1,346 @@
+// Package memory provides an in-memory implementation of the bot storage for tests and local development
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zokiio/mukabi/internal/wow"
+	"github.com/zokiio/mukabi/service/bot/db"
+)
+
+// server is a stored Discord server
+type server struct {
+	name   string
+	leftAt *time.Time
+}
+
+// characterKey identifies a character like the primary key of the wow_characters table
+type characterKey struct {
+	discordID string
+	serverID  string
+	region    string
+	realm     string
+	key       string // wow.CharacterKey of the name
+}
+
+// newCharacterKey returns the key of a normalized character
+func newCharacterKey(serverID, discordID string, c db.WoWCharacter) characterKey {
+	return characterKey{
+		discordID: discordID,
+		serverID:  serverID,
+		region:    c.Region,
+		realm:     c.Realm,
+		key:       wow.CharacterKey(c.CharacterName),
+	}
+}
+
+// normalize validates the region and converts the character to its canonical form like the database,
+// keeping the realm slug as is
+func normalize(c db.WoWCharacter) (db.WoWCharacter, error) {
+	region, err := wow.ParseRegion(c.Region)
+	if err != nil {
+		return db.WoWCharacter{}, err
+	}
+	return db.WoWCharacter{
+		CharacterName: strings.TrimSpace(c.CharacterName),
+		Region:        region.Code,
+		Realm:         strings.TrimSpace(c.Realm),
+	}, nil
+}
+
+// character is a stored World of Warcraft character
+type character struct {
+	db.WoWCharacter
+	deletedAt *time.Time
+}
+
+// Store is an in-memory db.Store with the same semantics as the database.
+// Queries never block, but fail with the context error once ctx is done.
+type Store struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	servers    map[string]*server
+	characters map[characterKey]*character
+	closed     bool
+}
+
+var _ db.Store = (*Store)(nil)
+
+// New creates an empty in-memory store
+func New() *Store {
+	return &Store{
+		now:        time.Now,
+		servers:    map[string]*server{},
+		characters: map[characterKey]*character{},
+	}
+}
+
+// SetClock replaces the clock used for left and deleted timestamps, e.g. to test retention
+func (s *Store) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Ping reports an error once the store is closed
+func (s *Store) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return fmt.Errorf("store is closed")
+	}
+	return nil
+}
+
+// Close marks the store as closed
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+// RegisterServer ensures a server exists and clears its left marker
+func (s *Store) RegisterServer(ctx context.Context, serverID, serverName string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registerServer(serverID, serverName)
+	return nil
+}
+
+// registerServer upserts a server, keeping the stored name if the new one is empty. s.mu must be held.
+func (s *Store) registerServer(serverID, serverName string) {
+	srv, ok := s.servers[serverID]
+	if !ok {
+		s.servers[serverID] = &server{name: serverName}
+		return
+	}
+	if serverName != "" {
+		srv.name = serverName
+	}
+	srv.leftAt = nil
+}
+
+// MarkServerLeft records that the bot has left a server
+func (s *Store) MarkServerLeft(ctx context.Context, serverID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if srv, ok := s.servers[serverID]; ok && srv.leftAt == nil {
+		now := s.now()
+		srv.leftAt = &now
+	}
+	return nil
+}
+
+// ReconcileServers registers all given servers and marks every other active server as left
+func (s *Store) ReconcileServers(ctx context.Context, servers []db.Server) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := make(map[string]bool, len(servers))
+	for _, srv := range servers {
+		current[srv.ID] = true
+		s.registerServer(srv.ID, srv.Name)
+	}
+
+	var left int64
+	now := s.now()
+	for id, srv := range s.servers {
+		if !current[id] && srv.leftAt == nil {
+			srv.leftAt = &now
+			left++
+		}
+	}
+	return left, nil
+}
+
+// ServerExists checks if a server is registered and the bot has not left it
+func (s *Store) ServerExists(ctx context.Context, serverID string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	srv, ok := s.servers[serverID]
+	return ok && srv.leftAt == nil, nil
+}
+
+// WoWRegisterCharacter stores a new character, restoring it if it was soft-deleted
+func (s *Store) WoWRegisterCharacter(ctx context.Context, serverID, discordID string, c db.WoWCharacter) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c, err := normalize(c)
+	if err != nil {
+		return fmt.Errorf("failed to register character: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.servers[serverID]; !ok {
+		return fmt.Errorf("failed to register character: unknown server %s", serverID)
+	}
+
+	key := newCharacterKey(serverID, discordID, c)
+	if existing, ok := s.characters[key]; ok && existing.deletedAt == nil {
+		return fmt.Errorf("failed to register character: %w", db.ErrCharacterExists)
+	}
+	s.characters[key] = &character{WoWCharacter: c}
+	return nil
+}
+
+// WoWGetCharacters returns all characters of a user in a server, ordered by name
+func (s *Store) WoWGetCharacters(ctx context.Context, serverID, discordID string) ([]db.WoWCharacter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var characters []db.WoWCharacter
+	for key, c := range s.characters {
+		if key.serverID == serverID && key.discordID == discordID && c.deletedAt == nil {
+			characters = append(characters, c.WoWCharacter)
+		}
+	}
+	slices.SortFunc(characters, func(a, b db.WoWCharacter) int {
+		return cmp.Or(
+			strings.Compare(wow.CharacterKey(a.CharacterName), wow.CharacterKey(b.CharacterName)),
+			strings.Compare(a.Region, b.Region),
+			strings.Compare(a.Realm, b.Realm),
+		)
+	})
+	return characters, nil
+}
+
+// WoWGetCharacter returns the character with the region, realm and case-insensitive name of the given one,
+// or an error wrapping db.ErrCharacterNotFound
+func (s *Store) WoWGetCharacter(ctx context.Context, serverID, discordID string, character db.WoWCharacter) (db.WoWCharacter, error) {
+	if err := ctx.Err(); err != nil {
+		return db.WoWCharacter{}, err
+	}
+
+	character, err := normalize(character)
+	if err != nil {
+		return db.WoWCharacter{}, fmt.Errorf("failed to fetch character: %w: %w", db.ErrCharacterNotFound, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The realm is either the stored slug or a realm name typed by the user
+	c, ok := s.characters[newCharacterKey(serverID, discordID, character)]
+	if !ok || c.deletedAt != nil {
+		character.Realm = wow.RealmSlug(character.Realm)
+		c, ok = s.characters[newCharacterKey(serverID, discordID, character)]
+	}
+	if !ok || c.deletedAt != nil {
+		return db.WoWCharacter{}, fmt.Errorf("failed to fetch character: %w", db.ErrCharacterNotFound)
+	}
+	return c.WoWCharacter, nil
+}
+
+// WoWHasRegisteredCharacter checks if a user has registered any character in a server
+func (s *Store) WoWHasRegisteredCharacter(ctx context.Context, serverID, discordID string) (bool, error) {
+	characters, err := s.WoWGetCharacters(ctx, serverID, discordID)
+	return len(characters) > 0, err
+}
+
+// PurgeExpired removes servers left and characters soft-deleted before the cutoff.
+// Characters of purged servers are removed with them.
+func (s *Store) PurgeExpired(ctx context.Context, cutoff time.Time) (db.PurgeResult, error) {
+	if err := ctx.Err(); err != nil {
+		return db.PurgeResult{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result db.PurgeResult
+	for id, srv := range s.servers {
+		if srv.leftAt != nil && srv.leftAt.Before(cutoff) {
+			delete(s.servers, id)
+			result.Servers++
+			for key := range s.characters {
+				if key.serverID == id {
+					delete(s.characters, key)
+				}
+			}
+		}
+	}
+
+	for key, c := range s.characters {
+		if c.deletedAt != nil && c.deletedAt.Before(cutoff) {
+			delete(s.characters, key)
+			result.Characters++
+		}
+	}
+	return result, nil
+}
+
+// SoftDeleteMember marks all characters of a user in a server as deleted
+func (s *Store) SoftDeleteMember(ctx context.Context, serverID, discordID string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	now := s.now()
+	for key, c := range s.characters {
+		if key.serverID == serverID && key.discordID == discordID && c.deletedAt == nil {
+			c.deletedAt = &now
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// DeleteUserData permanently removes all characters of a user across all servers
+func (s *Store) DeleteUserData(ctx context.Context, discordID string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for key := range s.characters {
+		if key.discordID == discordID {
+			delete(s.characters, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
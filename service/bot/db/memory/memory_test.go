@@ -0,0 +1,13 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/zokiio/mukabi/service/bot/db"
+	"github.com/zokiio/mukabi/service/bot/db/dbtest"
+	"github.com/zokiio/mukabi/service/bot/db/memory"
+)
+
+func TestMemoryStore(t *testing.T) {
+	dbtest.Run(t, func(*testing.T) db.Store { return memory.New() })
+}
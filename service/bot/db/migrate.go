@@ -0,0 +1,152 @@
+// Package db provides one-off data migrations applied after the schema
+package db
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zokiio/mukabi/internal/wow"
+)
+
+// legacyCharacter is a row of a wow_characters table keyed on the raw character name
+type legacyCharacter struct {
+	DiscordID     string     `db:"discord_id"`
+	ServerID      string     `db:"server_id"`
+	CharacterName string     `db:"character_name"`
+	Region        string     `db:"region"`
+	Realm         string     `db:"realm"`
+	DeletedAt     *time.Time `db:"deleted_at"`
+}
+
+// characterIdentity is the primary key of a character after the migration
+type characterIdentity struct {
+	discordID string
+	serverID  string
+	region    string
+	realm     string
+	key       string
+}
+
+// region returns the region code of the character, keeping unknown regions lowercased
+func (c legacyCharacter) region() string {
+	if region, ok := wow.LookupRegion(c.Region); ok {
+		return region.Code
+	}
+	return strings.ToLower(strings.TrimSpace(c.Region))
+}
+
+// identity returns the primary key of the character after the migration
+func (c legacyCharacter) identity() characterIdentity {
+	return characterIdentity{c.DiscordID, c.ServerID, c.region(), wow.RealmSlug(c.Realm), wow.CharacterKey(c.CharacterName)}
+}
+
+// migrate applies data migrations that cannot be expressed in the idempotent schema
+func migrate(ctx context.Context, db *sqlx.DB) error {
+	return migrateCharacterIdentity(ctx, db)
+}
+
+// migrateCharacterIdentity moves tables keyed on the raw character name to the region, realm slug
+// and case-insensitive character key. Characters on the same realm differing only in case are merged,
+// keeping an active one over a soft-deleted one and capitalized spellings over lowercase ones.
+// It does nothing once the character name is no longer part of the primary key.
+func migrateCharacterIdentity(ctx context.Context, db *sqlx.DB) error {
+	var legacy bool
+	if err := db.QueryRowContext(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM information_schema.key_column_usage
+			WHERE table_schema = current_schema() AND table_name = 'wow_characters'
+				AND constraint_name = 'wow_characters_pkey' AND column_name = 'character_name'
+		)`,
+	).Scan(&legacy); err != nil {
+		return fmt.Errorf("failed to check character identity migration: %w", err)
+	}
+	if !legacy {
+		return nil
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var characters []legacyCharacter
+	if err := tx.SelectContext(ctx, &characters,
+		`SELECT discord_id, server_id, character_name, COALESCE(region, '') AS region, COALESCE(realm, '') AS realm, deleted_at
+		FROM wow_characters
+		FOR UPDATE`,
+	); err != nil {
+		return fmt.Errorf("failed to read characters: %w", err)
+	}
+
+	// Sort the preferred character of every identity first
+	slices.SortFunc(characters, func(a, b legacyCharacter) int {
+		x, y := a.identity(), b.identity()
+		if c := cmp.Or(
+			cmp.Compare(x.discordID, y.discordID),
+			cmp.Compare(x.serverID, y.serverID),
+			cmp.Compare(x.region, y.region),
+			cmp.Compare(x.realm, y.realm),
+			cmp.Compare(x.key, y.key),
+		); c != 0 {
+			return c
+		}
+		if (a.DeletedAt == nil) != (b.DeletedAt == nil) {
+			if a.DeletedAt == nil {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.CharacterName, b.CharacterName)
+	})
+
+	var merged int
+	for i, character := range characters {
+		if i > 0 && characters[i-1].identity() == character.identity() {
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM wow_characters
+				WHERE discord_id = $1 AND server_id = $2 AND character_name = $3`,
+				character.DiscordID, character.ServerID, character.CharacterName,
+			); err != nil {
+				return fmt.Errorf("failed to merge character %s: %w", character.CharacterName, err)
+			}
+			merged++
+			continue
+		}
+
+		identity := character.identity()
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE wow_characters
+			SET character_key = $4, region = $5, realm = $6
+			WHERE discord_id = $1 AND server_id = $2 AND character_name = $3`,
+			character.DiscordID, character.ServerID, character.CharacterName,
+			identity.key, identity.region, identity.realm,
+		); err != nil {
+			return fmt.Errorf("failed to migrate character %s: %w", character.CharacterName, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`ALTER TABLE wow_characters ALTER COLUMN character_key SET NOT NULL;
+		ALTER TABLE wow_characters DROP CONSTRAINT wow_characters_pkey;
+		ALTER TABLE wow_characters ADD CONSTRAINT wow_characters_pkey PRIMARY KEY (discord_id, server_id, region, realm, character_key);`,
+	); err != nil {
+		return fmt.Errorf("failed to change character primary key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger().Info("Migrated character identities",
+		slog.Int("characters", len(characters)-merged),
+		slog.Int("merged", merged),
+	)
+	return nil
+}
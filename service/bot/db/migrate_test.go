@@ -0,0 +1,117 @@
+package db_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zokiio/mukabi/service/bot"
+	"github.com/zokiio/mukabi/service/bot/db"
+	"github.com/zokiio/mukabi/service/bot/db/dbtest"
+)
+
+// legacySchema is the original schema, keying characters on the raw name and storing realm names
+const legacySchema = `
+CREATE TABLE servers (
+    server_id TEXT PRIMARY KEY,
+    server_name TEXT
+);
+
+CREATE TABLE wow_characters (
+    discord_id TEXT,
+    server_id TEXT,
+    character_name TEXT,
+    region TEXT,
+    realm TEXT,
+    PRIMARY KEY (discord_id, server_id, character_name),
+    FOREIGN KEY (server_id) REFERENCES servers(server_id)
+);
+
+INSERT INTO servers (server_id, server_name) VALUES ('100', 'Guild');
+
+INSERT INTO wow_characters (discord_id, server_id, character_name, region, realm) VALUES
+    ('1', '100', 'zoki', 'EU', 'Twisting Nether'),
+    ('1', '100', 'Zoki', 'eu', 'twisting-nether'),
+    ('1', '100', 'Mukabi', 'US', 'Area 52'),
+    ('2', '100', 'zoki', 'eu', 'Aggra (Português)');
+`
+
+// TestMigrateCharacterIdentity is skipped unless a test database is configured, see dbtest.PostgresEnvPrefix.
+// It runs in its own Postgres schema, so it does not touch the tables of the conformance suite.
+func TestMigrateCharacterIdentity(t *testing.T) {
+	ctx := context.Background()
+	cfg := dbtest.PostgresConfig(t)
+
+	admin, err := sqlx.Open("pgx", cfg.PostgresDataSourceName())
+	if err != nil {
+		t.Fatalf("failed to open database: %s", err)
+	}
+	defer admin.Close()
+
+	schema := "mukabi_migrate_" + strconv.FormatUint(rand.Uint64(), 36)
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("failed to create schema: %s", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("failed to drop schema: %s", err)
+		}
+	})
+	cfg.URL = withSearchPath(cfg.PostgresDataSourceName(), schema)
+
+	legacy, err := sqlx.Open("pgx", cfg.URL)
+	if err != nil {
+		t.Fatalf("failed to open database: %s", err)
+	}
+	defer legacy.Close()
+	if _, err := legacy.ExecContext(ctx, legacySchema); err != nil {
+		t.Fatalf("failed to create legacy tables: %s", err)
+	}
+
+	want := map[string][]db.WoWCharacter{
+		// Names differing only in case are merged, keeping the capitalized spelling
+		"1": {
+			{CharacterName: "Mukabi", Region: "us", Realm: "area-52"},
+			{CharacterName: "Zoki", Region: "eu", Realm: "twisting-nether"},
+		},
+		"2": {
+			{CharacterName: "zoki", Region: "eu", Realm: "aggra-portugues"},
+		},
+	}
+
+	// Opening the database again leaves migrated tables unchanged
+	for range 2 {
+		database, err := bot.OpenDatabase(bot.Config{Database: cfg})
+		if err != nil {
+			t.Fatalf("OpenDatabase() error = %s", err)
+		}
+		for user, characters := range want {
+			got, err := database.WoWGetCharacters(ctx, "100", user)
+			if err != nil {
+				t.Fatalf("WoWGetCharacters(%s) error = %s", user, err)
+			}
+			if !reflect.DeepEqual(got, characters) {
+				t.Errorf("WoWGetCharacters(%s) = %+v, want %+v", user, got, characters)
+			}
+		}
+		if err := database.Close(); err != nil {
+			t.Fatalf("Close() error = %s", err)
+		}
+	}
+}
+
+// withSearchPath adds a search_path runtime parameter to a Postgres URL or key/value DSN
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && strings.HasPrefix(u.Scheme, "postgres") {
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	return dsn + " search_path=" + schema
+}
@@ -0,0 +1,82 @@
+// Package db provides database operations for data retention and user data removal
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PurgeResult summarizes the rows removed by a retention purge
+type PurgeResult struct {
+	Servers    int64 // Departed servers removed, including their characters
+	Characters int64 // Soft-deleted characters removed
+}
+
+// PurgeExpired permanently removes servers the bot left and characters soft-deleted before the cutoff.
+// Characters of purged servers are removed by the cascading foreign key.
+func (d *Database) PurgeExpired(ctx context.Context, cutoff time.Time) (PurgeResult, error) {
+	ctx, done := d.query(ctx, "purge_expired")
+	defer done()
+
+	var result PurgeResult
+
+	res, err := d.db.ExecContext(ctx,
+		`DELETE FROM servers 
+		WHERE left_at IS NOT NULL AND left_at < $1`,
+		cutoff.UTC(),
+	)
+	if err != nil {
+		return result, fmt.Errorf("failed to purge departed servers: %w", err)
+	}
+	if result.Servers, err = res.RowsAffected(); err != nil {
+		return result, fmt.Errorf("failed to count purged servers: %w", err)
+	}
+
+	res, err = d.db.ExecContext(ctx,
+		`DELETE FROM wow_characters 
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1`,
+		cutoff.UTC(),
+	)
+	if err != nil {
+		return result, fmt.Errorf("failed to purge deleted characters: %w", err)
+	}
+	if result.Characters, err = res.RowsAffected(); err != nil {
+		return result, fmt.Errorf("failed to count purged characters: %w", err)
+	}
+
+	return result, nil
+}
+
+// SoftDeleteMember marks all characters of a Discord user in a server as deleted
+func (d *Database) SoftDeleteMember(ctx context.Context, serverID, discordID string) (int64, error) {
+	ctx, done := d.query(ctx, "soft_delete_member")
+	defer done()
+
+	res, err := d.db.ExecContext(ctx,
+		`UPDATE wow_characters 
+		SET deleted_at = CURRENT_TIMESTAMP 
+		WHERE server_id = $1 AND discord_id = $2 AND deleted_at IS NULL`,
+		serverID, discordID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to soft-delete member characters: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// DeleteUserData permanently removes all data stored for a Discord user across all servers
+func (d *Database) DeleteUserData(ctx context.Context, discordID string) (int64, error) {
+	ctx, done := d.query(ctx, "delete_user_data")
+	defer done()
+
+	res, err := d.db.ExecContext(ctx,
+		`DELETE FROM wow_characters 
+		WHERE discord_id = $1`,
+		discordID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete user data: %w", err)
+	}
+	return res.RowsAffected()
+}
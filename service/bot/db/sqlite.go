@@ -0,0 +1,45 @@
+// Package db provides the SQLite driver of the database
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteBusyTimeout is how long a connection waits for another one to release the database lock
+const sqliteBusyTimeout = 5 * time.Second
+
+// SQLiteDataSourceName returns the SQLite connection string.
+// The URL is returned as is if set, otherwise the database path is opened with foreign keys enabled,
+// a busy timeout and transactions taking the write lock immediately.
+func (c Config) SQLiteDataSourceName() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	return fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=%d&_txlock=immediate",
+		c.Database,
+		sqliteBusyTimeout.Milliseconds(),
+	)
+}
+
+func openSQLite(cfg Config) (*sqlx.DB, error) {
+	db, err := sqlx.Open("sqlite3", cfg.SQLiteDataSourceName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return db, nil
+}
+
+// utc converts a timestamp to UTC before it is stored or compared.
+// SQLite stores timestamps as text and compares them lexically, which only orders them
+// correctly if they share the time zone of CURRENT_TIMESTAMP.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
@@ -0,0 +1,63 @@
+// Package db provides database access and operations for the bot
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCharacterNotFound is returned when a requested character is not registered
+var ErrCharacterNotFound = errors.New("character not found")
+
+// ServerStore persists the Discord servers the bot is a member of
+type ServerStore interface {
+	// RegisterServer ensures a server exists and clears its left marker
+	RegisterServer(ctx context.Context, serverID, serverName string) error
+	// MarkServerLeft records that the bot has left a server
+	MarkServerLeft(ctx context.Context, serverID string) error
+	// ReconcileServers registers all given servers and marks every other active server as left
+	ReconcileServers(ctx context.Context, servers []Server) (int64, error)
+	// ServerExists checks if a server is registered and the bot has not left it
+	ServerExists(ctx context.Context, serverID string) (bool, error)
+}
+
+// CharacterStore persists World of Warcraft characters registered by Discord users
+type CharacterStore interface {
+	// WoWRegisterCharacter stores a new character with its realm as slug, returning ErrCharacterExists
+	// if a character with the same region, realm and case-insensitive name is registered
+	WoWRegisterCharacter(ctx context.Context, serverID, discordID string, character WoWCharacter) error
+	// WoWGetCharacters returns all characters of a user in a server, ordered by name, region and realm
+	WoWGetCharacters(ctx context.Context, serverID, discordID string) ([]WoWCharacter, error)
+	// WoWGetCharacter returns the character with the region, realm and case-insensitive name of the given one,
+	// or an error wrapping ErrCharacterNotFound
+	WoWGetCharacter(ctx context.Context, serverID, discordID string, character WoWCharacter) (WoWCharacter, error)
+	// WoWHasRegisteredCharacter checks if a user has registered any character in a server
+	WoWHasRegisteredCharacter(ctx context.Context, serverID, discordID string) (bool, error)
+}
+
+// RetentionStore removes data of departed servers and members
+type RetentionStore interface {
+	// PurgeExpired permanently removes servers left and characters soft-deleted before the cutoff
+	PurgeExpired(ctx context.Context, cutoff time.Time) (PurgeResult, error)
+	// SoftDeleteMember marks all characters of a user in a server as deleted
+	SoftDeleteMember(ctx context.Context, serverID, discordID string) (int64, error)
+	// DeleteUserData permanently removes all characters of a user across all servers
+	DeleteUserData(ctx context.Context, discordID string) (int64, error)
+}
+
+// Store combines all storage used by the bot.
+// It is implemented by Database and by the in-memory store in the db/memory package.
+// Every method stops when ctx is done; errors caused by an exceeded deadline satisfy IsTimeout.
+type Store interface {
+	ServerStore
+	CharacterStore
+	RetentionStore
+
+	// Ping verifies the store is reachable
+	Ping(ctx context.Context) error
+	// Close releases the resources of the store
+	Close() error
+}
+
+var _ Store = (*Database)(nil)
@@ -2,37 +2,85 @@
 package db
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"log/slog"
+	"strings"
+
+	"github.com/zokiio/mukabi/internal/wow"
 )
 
-// WoWCharacter represents a World of Warcraft character in the database
+// ErrCharacterExists is returned when registering a character that is already registered
+var ErrCharacterExists = errors.New("character already registered")
+
+// WoWCharacter represents a World of Warcraft character in the database.
+// Characters are identified by region, realm slug and case-insensitive name, see wow.CharacterKey.
 type WoWCharacter struct {
-	CharacterName string
-	Region        string
-	Realm         string
+	CharacterName string // Name as returned by Raider.IO
+	Region        string // Region code
+	Realm         string // Realm slug
+}
+
+// normalize validates the region and converts the character to its canonical form.
+// The realm is kept as is, it is the slug Raider.IO knows the character by.
+func (c WoWCharacter) normalize() (WoWCharacter, error) {
+	region, err := wow.ParseRegion(c.Region)
+	if err != nil {
+		return WoWCharacter{}, err
+	}
+	return WoWCharacter{
+		CharacterName: strings.TrimSpace(c.CharacterName),
+		Region:        region.Code,
+		Realm:         strings.TrimSpace(c.Realm),
+	}, nil
 }
 
-// WoWRegisterCharacter stores a new World of Warcraft character for a Discord user
-func (d *Database) WoWRegisterCharacter(serverID, discordID string, character WoWCharacter) error {
-	_, err := d.db.Exec(
-		`INSERT INTO wow_characters (server_id, discord_id, character_name, region, realm) 
-		VALUES ($1, $2, $3, $4, $5)`,
-		serverID, discordID, character.CharacterName, character.Region, character.Realm,
+// WoWRegisterCharacter stores a new World of Warcraft character for a Discord user.
+// The realm must be the Raider.IO slug and is stored unchanged. Names on the same realm differing only in case are the same character.
+// It returns an error wrapping wow.ErrUnknownRegion if the region is not supported.
+func (d *Database) WoWRegisterCharacter(ctx context.Context, serverID, discordID string, character WoWCharacter) error {
+	ctx, done := d.query(ctx, "wow_register_character")
+	defer done()
+
+	character, err := character.normalize()
+	if err != nil {
+		return fmt.Errorf("failed to register character: %w", err)
+	}
+
+	// Soft-deleted characters are restored when registered again
+	res, err := d.db.ExecContext(ctx,
+		`INSERT INTO wow_characters (server_id, discord_id, character_name, character_key, region, realm) 
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (discord_id, server_id, region, realm, character_key) DO UPDATE 
+		SET character_name = EXCLUDED.character_name, deleted_at = NULL 
+		WHERE wow_characters.deleted_at IS NOT NULL`,
+		serverID, discordID, character.CharacterName, wow.CharacterKey(character.CharacterName), character.Region, character.Realm,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to register character: %w", err)
 	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to register character: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("failed to register character: %w", ErrCharacterExists)
+	}
 	return nil
 }
 
 // WoWGetCharacters retrieves all World of Warcraft characters registered for a Discord user
-func (d *Database) WoWGetCharacters(serverID, discordID string) ([]WoWCharacter, error) {
+func (d *Database) WoWGetCharacters(ctx context.Context, serverID, discordID string) ([]WoWCharacter, error) {
+	ctx, done := d.query(ctx, "wow_get_characters")
+	defer done()
+
 	var characters []WoWCharacter
-	rows, err := d.db.Query(
+	rows, err := d.db.QueryContext(ctx,
 		`SELECT character_name, region, realm 
 		FROM wow_characters 
-		WHERE server_id = $1 AND discord_id = $2`,
+		WHERE server_id = $1 AND discord_id = $2 AND deleted_at IS NULL 
+		ORDER BY character_key, region, realm`,
 		serverID, discordID,
 	)
 	if err != nil {
@@ -43,36 +91,55 @@ func (d *Database) WoWGetCharacters(serverID, discordID string) ([]WoWCharacter,
 	for rows.Next() {
 		var character WoWCharacter
 		if err := rows.Scan(&character.CharacterName, &character.Region, &character.Realm); err != nil {
-			slog.Error("Error scanning character row", "error", err)
+			logger().Error("Error scanning character row", "error", err)
 			return nil, fmt.Errorf("failed to scan character row: %w", err)
 		}
 		characters = append(characters, character)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch characters: %w", err)
+	}
 	return characters, nil
 }
 
-// WoWGetCharacter retrieves a specific World of Warcraft character for a Discord user
-func (d *Database) WoWGetCharacter(serverID, discordID, characterName string) (WoWCharacter, error) {
-	var character WoWCharacter
-	err := d.db.QueryRow(
+// WoWGetCharacter retrieves a specific World of Warcraft character for a Discord user by region,
+// realm and name, ignoring the case of the name. The realm is either the stored slug or a realm
+// name typed by the user, which is converted to its slug.
+func (d *Database) WoWGetCharacter(ctx context.Context, serverID, discordID string, character WoWCharacter) (WoWCharacter, error) {
+	ctx, done := d.query(ctx, "wow_get_character")
+	defer done()
+
+	character, err := character.normalize()
+	if err != nil {
+		return WoWCharacter{}, fmt.Errorf("failed to fetch character: %w: %w", ErrCharacterNotFound, err)
+	}
+
+	err = d.db.QueryRowContext(ctx,
 		`SELECT character_name, region, realm 
 		FROM wow_characters 
-		WHERE server_id = $1 AND discord_id = $2 AND character_name = $3`,
-		serverID, discordID, characterName,
+		WHERE server_id = $1 AND discord_id = $2 AND region = $3 AND realm IN ($4, $5) AND character_key = $6 AND deleted_at IS NULL 
+		ORDER BY realm = $4 DESC 
+		LIMIT 1`,
+		serverID, discordID, character.Region, character.Realm, wow.RealmSlug(character.Realm), wow.CharacterKey(character.CharacterName),
 	).Scan(&character.CharacterName, &character.Region, &character.Realm)
-	if err != nil {
+	if errors.Is(err, sql.ErrNoRows) {
+		return WoWCharacter{}, fmt.Errorf("failed to fetch character: %w", ErrCharacterNotFound)
+	} else if err != nil {
 		return WoWCharacter{}, fmt.Errorf("failed to fetch character: %w", err)
 	}
 	return character, nil
 }
 
 // WoWHasRegisteredCharacter checks if a Discord user has any registered World of Warcraft characters
-func (d *Database) WoWHasRegisteredCharacter(serverID, discordID string) (bool, error) {
+func (d *Database) WoWHasRegisteredCharacter(ctx context.Context, serverID, discordID string) (bool, error) {
+	ctx, done := d.query(ctx, "wow_has_registered_character")
+	defer done()
+
 	var count int
-	err := d.db.QueryRow(
+	err := d.db.QueryRowContext(ctx,
 		`SELECT COUNT(*) 
 		FROM wow_characters 
-		WHERE server_id = $1 AND discord_id = $2`,
+		WHERE server_id = $1 AND discord_id = $2 AND deleted_at IS NULL`,
 		serverID, discordID,
 	).Scan(&count)
 	if err != nil {
@@ -0,0 +1,166 @@
+// Package embeds provides Discord embed creation utilities
+package embeds
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/zokiio/mukabi/service/bot/i18n"
+)
+
+// HelpOverview creates an embed listing all available commands with their descriptions
+func HelpOverview(locale discord.Locale, defs []discord.ApplicationCommandCreate) discord.Embed {
+	var sb strings.Builder
+	for _, def := range defs {
+		fmt.Fprintf(&sb, "%s - %s\n", commandLabel(def), commandDescription(locale, def))
+	}
+
+	return discord.Embed{
+		Type:        discord.EmbedTypeRich,
+		Title:       i18n.T(locale, "help.title"),
+		Description: sb.String(),
+		Color:       ColorPrimary,
+		Footer: &discord.EmbedFooter{
+			Text: i18n.T(locale, "help.footer"),
+		},
+	}
+}
+
+// HelpCommand creates an embed describing a single command, its subcommands and options
+func HelpCommand(locale discord.Locale, def discord.ApplicationCommandCreate) discord.Embed {
+	var sb strings.Builder
+	sb.WriteString(commandDescription(locale, def))
+	sb.WriteString("\n")
+
+	if slash, ok := def.(discord.SlashCommandCreate); ok {
+		writeOptions(&sb, locale, "/"+slash.Name, slash.Options)
+	}
+
+	return discord.Embed{
+		Type:        discord.EmbedTypeRich,
+		Title:       commandLabel(def),
+		Description: sb.String(),
+		Color:       ColorPrimary,
+	}
+}
+
+// commandLabel returns how a command is invoked by users
+func commandLabel(def discord.ApplicationCommandCreate) string {
+	if def.Type() == discord.ApplicationCommandTypeSlash {
+		return "`/" + def.CommandName() + "`"
+	}
+	return "`" + def.CommandName() + "`"
+}
+
+// commandDescription returns the localized description of a command, or a hint on how to use context-menu commands
+func commandDescription(locale discord.Locale, def discord.ApplicationCommandCreate) string {
+	switch d := def.(type) {
+	case discord.SlashCommandCreate:
+		return localized(locale, d.Description, d.DescriptionLocalizations)
+	case discord.UserCommandCreate:
+		return i18n.T(locale, "help.user_command", localized(locale, d.Name, d.NameLocalizations))
+	case discord.MessageCommandCreate:
+		return i18n.T(locale, "help.message_command", localized(locale, d.Name, d.NameLocalizations))
+	default:
+		return ""
+	}
+}
+
+// localized returns the localization for the locale if present, otherwise the default text
+func localized(locale discord.Locale, text string, localizations map[discord.Locale]string) string {
+	if l, ok := localizations[locale]; ok {
+		return l
+	}
+	return text
+}
+
+// writeOptions renders subcommands and options of a slash command recursively
+func writeOptions(sb *strings.Builder, locale discord.Locale, path string, options []discord.ApplicationCommandOption) {
+	for _, option := range options {
+		switch o := option.(type) {
+		case *discord.ApplicationCommandOptionSubCommandGroup:
+			for _, sub := range o.Options {
+				writeOptions(sb, locale, path+" "+o.Name, []discord.ApplicationCommandOption{&sub})
+			}
+		case *discord.ApplicationCommandOptionSubCommand:
+			fmt.Fprintf(sb, "\n**`%s %s`** - %s\n", path, o.Name, localized(locale, o.Description, o.DescriptionLocalizations))
+			writeOptions(sb, locale, path+" "+o.Name, o.Options)
+		default:
+			fmt.Fprintf(sb, "- `%s`%s - %s%s\n",
+				option.OptionName(),
+				optionRequired(locale, option),
+				optionDescription(locale, option),
+				optionChoices(locale, option),
+			)
+		}
+	}
+}
+
+// optionDescription returns the localized description of an option
+func optionDescription(locale discord.Locale, option discord.ApplicationCommandOption) string {
+	switch o := option.(type) {
+	case *discord.ApplicationCommandOptionString:
+		return localized(locale, o.Description, o.DescriptionLocalizations)
+	case *discord.ApplicationCommandOptionInt:
+		return localized(locale, o.Description, o.DescriptionLocalizations)
+	case *discord.ApplicationCommandOptionFloat:
+		return localized(locale, o.Description, o.DescriptionLocalizations)
+	default:
+		return option.OptionDescription()
+	}
+}
+
+// optionRequired returns a marker for required options
+func optionRequired(locale discord.Locale, option discord.ApplicationCommandOption) string {
+	var required bool
+	switch o := option.(type) {
+	case *discord.ApplicationCommandOptionString:
+		required = o.Required
+	case *discord.ApplicationCommandOptionInt:
+		required = o.Required
+	case *discord.ApplicationCommandOptionFloat:
+		required = o.Required
+	case *discord.ApplicationCommandOptionBool:
+		required = o.Required
+	case *discord.ApplicationCommandOptionUser:
+		required = o.Required
+	case *discord.ApplicationCommandOptionChannel:
+		required = o.Required
+	case *discord.ApplicationCommandOptionRole:
+		required = o.Required
+	case *discord.ApplicationCommandOptionMentionable:
+		required = o.Required
+	case *discord.ApplicationCommandOptionAttachment:
+		required = o.Required
+	}
+
+	if required {
+		return " (" + i18n.T(locale, "help.required") + ")"
+	}
+	return ""
+}
+
+// optionChoices returns the list of predefined choices of an option, if any
+func optionChoices(locale discord.Locale, option discord.ApplicationCommandOption) string {
+	var choices []string
+	switch o := option.(type) {
+	case *discord.ApplicationCommandOptionString:
+		for _, choice := range o.Choices {
+			choices = append(choices, localized(locale, choice.Name, choice.NameLocalizations))
+		}
+	case *discord.ApplicationCommandOptionInt:
+		for _, choice := range o.Choices {
+			choices = append(choices, choice.Name)
+		}
+	case *discord.ApplicationCommandOptionFloat:
+		for _, choice := range o.Choices {
+			choices = append(choices, choice.Name)
+		}
+	}
+
+	if len(choices) == 0 {
+		return ""
+	}
+	return " (" + i18n.T(locale, "help.choices", strings.Join(choices, ", ")) + ")"
+}
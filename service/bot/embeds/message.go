@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/disgoorg/disgo/discord"
+	"github.com/zokiio/mukabi/service/bot/i18n"
 )
 
 // Message creates a basic message embed
@@ -24,12 +25,12 @@ func Messagef(format string, a ...any) discord.MessageCreate {
 	return Message(fmt.Sprintf(format, a...))
 }
 
-// Error creates an error message embed
-func Error(message string, a ...any) discord.MessageCreate {
+// Error creates an error message embed from a catalog key, localized for the given locale
+func Error(locale discord.Locale, key string, a ...any) discord.MessageCreate {
 	return discord.MessageCreate{
 		Embeds: []discord.Embed{
 			{
-				Description: fmt.Sprintf(message, a...),
+				Description: i18n.T(locale, key, a...),
 				Color:       ColorDanger,
 			},
 		},
@@ -38,6 +39,8 @@ func Error(message string, a ...any) discord.MessageCreate {
 }
 
 // ErrorWithErr creates an error message embed with error details
-func ErrorWithErr(message string, err error) discord.MessageCreate {
-	return Error(message + ": " + err.Error())
+func ErrorWithErr(locale discord.Locale, key string, err error) discord.MessageCreate {
+	msg := Error(locale, key)
+	msg.Embeds[0].Description += ": " + err.Error()
+	return msg
 }
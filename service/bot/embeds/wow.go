@@ -7,21 +7,30 @@ import (
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/zokiio/mukabi/external/raiderio"
+	"github.com/zokiio/mukabi/internal/wow"
+	"github.com/zokiio/mukabi/service/bot/i18n"
 )
 
 // Character creates an embed for a WoW character profile
-func Character(character *raiderio.CharacterProfile) discord.Embed {
+func Character(locale discord.Locale, character *raiderio.CharacterProfile) discord.Embed {
 	embed := discord.Embed{
 		Type:  discord.EmbedTypeRich,
 		Title: character.Name,
 		Color: ColorWoW,
 		Description: fmt.Sprintf(
-			"**Region:** %s\n**Realm:** %s\n**Faction:** %s\n**Class:** %s\n**Mythic+ Score:** %.2f\n**Raider.IO Profile:** [Link](%s)",
-			strings.ToUpper(character.Region),
+			"**%s:** %s\n**%s:** %s\n**%s:** %s\n**%s:** %s\n**%s:** %.2f\n**%s:** [%s](%s)",
+			i18n.T(locale, "character.region"),
+			regionName(character.Region),
+			i18n.T(locale, "character.realm"),
 			strings.ToUpper(character.Realm),
+			i18n.T(locale, "character.faction"),
 			strings.ToUpper(character.Faction),
+			i18n.T(locale, "character.class"),
 			strings.ToUpper(character.Class),
-			character.MythicPlusScoresBySeason[0].Scores.All,
+			i18n.T(locale, "character.score"),
+			CurrentScore(character),
+			i18n.T(locale, "character.profile"),
+			i18n.T(locale, "character.link"),
 			character.ProfileURL,
 		),
 	}
@@ -44,7 +53,7 @@ func Character(character *raiderio.CharacterProfile) discord.Embed {
 
 	if !character.LastCrawledAt.IsZero() {
 		embed.Footer = &discord.EmbedFooter{
-			Text: "Last crawled at " + character.LastCrawledAt.Format("2006-01-02 15:04:05"),
+			Text: i18n.T(locale, "character.last_crawled", character.LastCrawledAt.Format("2006-01-02 15:04:05")),
 		}
 	}
 
@@ -52,8 +61,72 @@ func Character(character *raiderio.CharacterProfile) discord.Embed {
 }
 
 // CharacterMessage creates a message embed for a WoW character profile
-func CharacterMessage(character *raiderio.CharacterProfile) discord.MessageCreate {
+func CharacterMessage(locale discord.Locale, character *raiderio.CharacterProfile) discord.MessageCreate {
 	return discord.MessageCreate{
-		Embeds: []discord.Embed{Character(character)},
+		Embeds: []discord.Embed{Character(locale, character)},
 	}
 }
+
+// CharacterSummary holds the condensed data shown for a character in a list
+type CharacterSummary struct {
+	Name       string
+	Region     string
+	Realm      string
+	Score      float64
+	ProfileURL string
+	Found      bool // Whether Raider.IO returned a profile for the character
+}
+
+// CharacterList creates an embed listing the registered characters of a Discord user
+func CharacterList(locale discord.Locale, owner string, characters []CharacterSummary) discord.Embed {
+	embed := discord.Embed{
+		Type:  discord.EmbedTypeRich,
+		Title: i18n.T(locale, "character.list_title", owner),
+		Color: ColorWoW,
+	}
+
+	if len(characters) == 0 {
+		embed.Description = i18n.T(locale, "character.list_empty")
+		return embed
+	}
+
+	var sb strings.Builder
+	for _, character := range characters {
+		name := character.Name
+		if character.ProfileURL != "" {
+			name = fmt.Sprintf("[%s](%s)", character.Name, character.ProfileURL)
+		}
+
+		score := i18n.T(locale, "character.score_unavailable")
+		if character.Found {
+			score = fmt.Sprintf("%.2f", character.Score)
+		}
+
+		fmt.Fprintf(&sb, "**%s** - %s %s\n%s: %s\n",
+			name,
+			regionName(character.Region),
+			strings.ToUpper(character.Realm),
+			i18n.T(locale, "character.score"),
+			score,
+		)
+	}
+	embed.Description = sb.String()
+
+	return embed
+}
+
+// CurrentScore returns the overall Mythic+ score of the current season, or zero if unknown
+func CurrentScore(character *raiderio.CharacterProfile) float64 {
+	if len(character.MythicPlusScoresBySeason) == 0 {
+		return 0
+	}
+	return character.MythicPlusScoresBySeason[0].Scores.All
+}
+
+// regionName returns the display name of a region code, falling back to the upper-cased code
+func regionName(code string) string {
+	if region, ok := wow.LookupRegion(code); ok {
+		return region.Name
+	}
+	return strings.ToUpper(code)
+}
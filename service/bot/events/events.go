@@ -3,16 +3,53 @@ package events
 
 import (
 	"log/slog"
+	"sync"
 
 	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 
+	"github.com/zokiio/mukabi/internal/log"
 	mubot "github.com/zokiio/mukabi/service/bot"
+	"github.com/zokiio/mukabi/service/bot/db"
 )
 
+// logger returns the logger of the events package, configurable as "events" in [log.levels]
+func logger() *slog.Logger {
+	return log.Named("events")
+}
+
 // EventHandler manages Discord event handling
 type EventHandler struct {
 	*mubot.Bot
+
+	shards shardTracker
+}
+
+// shardTracker records which shards have received their READY event.
+// Only then are the guilds of a shard known to the cache, at least as unready.
+type shardTracker struct {
+	mu    sync.Mutex
+	count int
+	ready map[int]bool
+}
+
+// setReady marks a shard as ready. shardCount is taken from the READY event, 0 means unsharded.
+func (t *shardTracker) setReady(shardID, shardCount int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ready == nil {
+		t.ready = map[int]bool{}
+	}
+	t.ready[shardID] = true
+	t.count = max(shardCount, 1)
+}
+
+// allReady reports whether every shard has received its READY event
+func (t *shardTracker) allReady() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.count > 0 && len(t.ready) >= t.count
 }
 
 // OnEvent implements bot.EventListener interface
@@ -20,46 +57,164 @@ func (h *EventHandler) OnEvent(event bot.Event) {
 	switch e := event.(type) {
 	case *events.GuildJoin:
 		h.handleGuildJoin(e)
+	case *events.GuildReady:
+		h.handleGuildReady(e)
+	case *events.GuildUpdate:
+		h.handleGuildUpdate(e)
+	case *events.GuildsReady:
+		h.handleGuildsReady(e)
 	case *events.GuildLeave:
 		h.handleGuildLeave(e)
+	case *events.GuildMemberLeave:
+		h.handleGuildMemberLeave(e)
 	case *events.Ready:
 		h.handleReady(e)
 	default:
-		slog.Debug("Received unhandled event type")
+		logger().Debug("Received unhandled event type")
 	}
 }
 
 // handleGuildJoin processes guild join events
 func (h *EventHandler) handleGuildJoin(event *events.GuildJoin) {
-	slog.Info("Bot joined guild",
+	logger().Info("Bot joined guild",
 		slog.String("guild_name", event.Guild.Name),
 		slog.String("guild_id", event.Guild.ID.String()),
 	)
 
-	if err := h.Database.RegisterServer(event.Guild.ID.String(), event.Guild.Name); err != nil {
-		slog.Error("Failed to register server in database",
-			slog.String("guild_id", event.Guild.ID.String()),
+	h.registerServer(event.Guild)
+}
+
+// handleGuildReady processes guild ready events sent for every guild after connecting
+func (h *EventHandler) handleGuildReady(event *events.GuildReady) {
+	logger().Debug("Guild ready",
+		slog.String("guild_name", event.Guild.Name),
+		slog.String("guild_id", event.Guild.ID.String()),
+	)
+
+	h.registerServer(event.Guild)
+}
+
+// handleGuildUpdate processes guild update events to keep server names current
+func (h *EventHandler) handleGuildUpdate(event *events.GuildUpdate) {
+	if event.OldGuild.Name == event.Guild.Name {
+		return
+	}
+
+	logger().Info("Guild renamed",
+		slog.String("old_name", event.OldGuild.Name),
+		slog.String("guild_name", event.Guild.Name),
+		slog.String("guild_id", event.Guild.ID.String()),
+	)
+
+	h.registerServer(event.Guild)
+}
+
+// handleGuildsReady reconciles the servers once the guilds received so far are loaded
+func (h *EventHandler) handleGuildsReady(event *events.GuildsReady) {
+	h.reconcileServers(event.Client(), event.ShardID())
+}
+
+// reconcileServers reconciles the servers table with the cached guild list.
+// It waits until every shard is ready and no guild is loading, since the cache lacks the guilds
+// of shards that have not connected yet and they would be marked as left.
+func (h *EventHandler) reconcileServers(client bot.Client, shardID int) {
+	caches := client.Caches()
+	if !h.shards.allReady() || len(caches.UnreadyGuildIDs()) > 0 {
+		logger().Debug("Waiting for all shards before reconciling servers", slog.Int("shard_id", shardID))
+		return
+	}
+
+	var servers []db.Server
+	caches.GuildsForEach(func(guild discord.Guild) {
+		servers = append(servers, db.Server{ID: guild.ID.String(), Name: guild.Name})
+	})
+
+	// Guilds that are unavailable or still loading are joined, but their names are unknown
+	for _, guildID := range append(caches.UnavailableGuildIDs(), caches.UnreadyGuildIDs()...) {
+		servers = append(servers, db.Server{ID: guildID.String()})
+	}
+
+	left, err := h.Database.ReconcileServers(h.Context(), servers)
+	if err != nil {
+		logger().Error("Failed to reconcile servers in database",
 			slog.String("error", err.Error()),
 		)
+		return
 	}
+
+	logger().Info("Reconciled servers",
+		slog.Int("shard_id", shardID),
+		slog.Int("active", len(servers)),
+		slog.Int64("left", left),
+	)
 }
 
 // handleGuildLeave processes guild leave events
 func (h *EventHandler) handleGuildLeave(event *events.GuildLeave) {
-	slog.Info("Bot left guild",
+	logger().Info("Bot left guild",
 		slog.String("guild_id", event.GuildID.String()),
 	)
+
+	if err := h.Database.MarkServerLeft(h.Context(), event.GuildID.String()); err != nil {
+		logger().Error("Failed to mark server as left in database",
+			slog.String("guild_id", event.GuildID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// handleGuildMemberLeave soft-deletes the characters of members leaving a guild
+func (h *EventHandler) handleGuildMemberLeave(event *events.GuildMemberLeave) {
+	if !h.Config.Retention.TrackMembers {
+		return
+	}
+
+	deleted, err := h.Database.SoftDeleteMember(h.Context(), event.GuildID.String(), event.User.ID.String())
+	if err != nil {
+		logger().Error("Failed to soft-delete member characters",
+			slog.String("guild_id", event.GuildID.String()),
+			slog.String("user_id", event.User.ID.String()),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	if deleted > 0 {
+		logger().Info("Member left guild, characters marked for deletion",
+			slog.String("guild_id", event.GuildID.String()),
+			slog.String("user_id", event.User.ID.String()),
+			slog.Int64("characters", deleted),
+		)
+	}
+}
+
+// registerServer upserts a guild in the servers table
+func (h *EventHandler) registerServer(guild discord.Guild) {
+	if err := h.Database.RegisterServer(h.Context(), guild.ID.String(), guild.Name); err != nil {
+		logger().Error("Failed to register server in database",
+			slog.String("guild_id", guild.ID.String()),
+			slog.String("error", err.Error()),
+		)
+	}
 }
 
 // handleReady processes the ready event when the bot connects to Discord
 func (h *EventHandler) handleReady(event *events.Ready) {
-	slog.Info("Bot is ready",
+	logger().Info("Bot is ready",
 		slog.String("username", event.User.Username),
 		slog.String("user_id", event.User.ID.String()),
+		slog.Int("shard_id", event.ShardID()),
 	)
+
+	h.shards.setReady(event.ShardID(), event.Shard[1])
+
+	// Shards without guilds never send GuildsReady
+	if len(event.Guilds) == 0 {
+		h.reconcileServers(event.Client(), event.ShardID())
+	}
 }
 
 // New creates a new event handler
 func New(bot *mubot.Bot) bot.EventListener {
-	return &EventHandler{bot}
+	return &EventHandler{Bot: bot}
 }
@@ -0,0 +1,100 @@
+// Package bot provides the core functionality for the Discord bot
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/topi314/tint"
+
+	"github.com/zokiio/mukabi/internal/metrics"
+)
+
+const (
+	defaultHTTPAddress = ":8080"
+	readinessTimeout   = 2 * time.Second
+)
+
+// HTTPConfig holds settings for the health, readiness and metrics HTTP server
+type HTTPConfig struct {
+	Enabled bool   `toml:"enabled"` // Start the HTTP server
+	Address string `toml:"address"` // Listen address, e.g. ':8080'
+}
+
+// String returns a string representation of the HTTP configuration
+func (c HTTPConfig) String() string {
+	return fmt.Sprintf("\n  Enabled: %t\n  Address: %s\n", c.Enabled, c.Address)
+}
+
+// startHTTP starts the HTTP server exposing /healthz, /readyz and /metrics in the background
+func (b *Bot) startHTTP() {
+	addr := b.Config.HTTP.Address
+	if addr == "" {
+		addr = defaultHTTPAddress
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("GET /readyz", b.handleReadyz)
+	mux.Handle("GET /metrics", metrics.Handler())
+
+	b.httpServer = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		slog.Info("Starting HTTP server", slog.String("address", addr))
+		if err := b.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP server failed", tint.Err(err))
+		}
+	}()
+}
+
+// handleReadyz reports ready when all shards are connected and the database responds
+func (b *Bot) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if err := b.ready(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte("ok"))
+}
+
+// ready returns an error describing the first failed readiness check
+func (b *Bot) ready(ctx context.Context) error {
+	shards := b.Discord.ShardManager().Shards()
+	if len(shards) == 0 {
+		return errors.New("no shards connected")
+	}
+	for id, shard := range shards {
+		if shard.Status() != gateway.StatusReady {
+			return fmt.Errorf("shard %d not ready: %s", id, shard.Status())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	if err := b.Database.Ping(ctx); err != nil {
+		return fmt.Errorf("database unavailable: %w", err)
+	}
+
+	return nil
+}
+
+// stopHTTP gracefully shuts down the HTTP server if it is running
+func (b *Bot) stopHTTP(ctx context.Context) {
+	if b.httpServer == nil {
+		return
+	}
+	if err := b.httpServer.Shutdown(ctx); err != nil {
+		slog.Error("Error shutting down HTTP server", tint.Err(err))
+	}
+}
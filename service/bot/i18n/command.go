@@ -0,0 +1,67 @@
+// Package i18n provides message catalogs and localization helpers for the bot
+package i18n
+
+import "github.com/disgoorg/disgo/discord"
+
+// LocalizeCommand populates the name and description localizations of a command definition.
+// Keys are derived from the command path, e.g. "commands.wow.reg-character.region.description",
+// and choices use "<option path>.choices.<value>".
+func LocalizeCommand(def discord.ApplicationCommandCreate) discord.ApplicationCommandCreate {
+	switch d := def.(type) {
+	case discord.SlashCommandCreate:
+		prefix := "commands." + d.Name
+		d.NameLocalizations = Localizations(prefix + ".name")
+		d.DescriptionLocalizations = Localizations(prefix + ".description")
+		localizeOptions(prefix, d.Options)
+		return d
+	case discord.UserCommandCreate:
+		d.NameLocalizations = Localizations("commands." + d.Name + ".name")
+		return d
+	case discord.MessageCommandCreate:
+		d.NameLocalizations = Localizations("commands." + d.Name + ".name")
+		return d
+	default:
+		return def
+	}
+}
+
+// localizeOptions populates localizations of slash command options recursively
+func localizeOptions(prefix string, options []discord.ApplicationCommandOption) {
+	for _, option := range options {
+		key := prefix + "." + option.OptionName()
+		names := Localizations(key + ".name")
+		descriptions := Localizations(key + ".description")
+
+		switch o := option.(type) {
+		case *discord.ApplicationCommandOptionSubCommandGroup:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			for i := range o.Options {
+				localizeOptions(key, []discord.ApplicationCommandOption{&o.Options[i]})
+			}
+		case *discord.ApplicationCommandOptionSubCommand:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			localizeOptions(key, o.Options)
+		case *discord.ApplicationCommandOptionString:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+			for i := range o.Choices {
+				o.Choices[i].NameLocalizations = Localizations(key + ".choices." + o.Choices[i].Value)
+			}
+		case *discord.ApplicationCommandOptionInt:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+		case *discord.ApplicationCommandOptionFloat:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+		case *discord.ApplicationCommandOptionBool:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+		case *discord.ApplicationCommandOptionUser:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+		case *discord.ApplicationCommandOptionChannel:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+		case *discord.ApplicationCommandOptionRole:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+		case *discord.ApplicationCommandOptionMentionable:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+		case *discord.ApplicationCommandOptionAttachment:
+			o.NameLocalizations, o.DescriptionLocalizations = names, descriptions
+		}
+	}
+}
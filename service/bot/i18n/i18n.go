@@ -0,0 +1,109 @@
+// Package i18n provides message catalogs and localization helpers for the bot
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// DefaultLocale is the locale used when a message is missing in the requested locale
+const DefaultLocale = discord.LocaleEnglishUS
+
+//go:embed locales/*.toml
+var localeFS embed.FS
+
+// catalogLocales maps catalog file names to the Discord locales they serve
+var catalogLocales = map[string][]discord.Locale{
+	"en": {discord.LocaleEnglishUS, discord.LocaleEnglishGB},
+	"de": {discord.LocaleGerman},
+	"fr": {discord.LocaleFrench},
+}
+
+// catalogs holds the flattened messages of every supported locale
+var catalogs = mustLoadCatalogs()
+
+// mustLoadCatalogs parses all embedded catalogs, panicking on malformed files
+func mustLoadCatalogs() map[discord.Locale]map[string]string {
+	loaded := make(map[discord.Locale]map[string]string)
+
+	for name, locales := range catalogLocales {
+		data, err := localeFS.ReadFile(path.Join("locales", name+".toml"))
+		if err != nil {
+			panic(fmt.Sprintf("failed to read locale catalog %s: %v", name, err))
+		}
+
+		var raw map[string]any
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			panic(fmt.Sprintf("failed to decode locale catalog %s: %v", name, err))
+		}
+
+		messages := make(map[string]string)
+		flatten("", raw, messages)
+		for _, locale := range locales {
+			loaded[locale] = messages
+		}
+	}
+
+	return loaded
+}
+
+// flatten converts nested catalog tables into dotted message keys
+func flatten(prefix string, raw map[string]any, out map[string]string) {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(key, v, out)
+		case string:
+			out[key] = v
+		}
+	}
+}
+
+// lookup returns the message for a key in the given locale, falling back to the default locale
+func lookup(locale discord.Locale, key string) (string, bool) {
+	if msg, ok := catalogs[locale][key]; ok {
+		return msg, true
+	}
+	msg, ok := catalogs[DefaultLocale][key]
+	return msg, ok
+}
+
+// T returns the localized message for a key, formatted with the provided arguments.
+// Missing keys are returned as-is so they are easy to spot.
+func T(locale discord.Locale, key string, a ...any) string {
+	msg, ok := lookup(locale, key)
+	if !ok {
+		return key
+	}
+	if len(a) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, a...)
+}
+
+// Localizations returns the translations of a key for every non-default locale that defines it
+func Localizations(key string) map[discord.Locale]string {
+	var localizations map[discord.Locale]string
+	for locale, messages := range catalogs {
+		if strings.HasPrefix(string(locale), "en") {
+			continue
+		}
+		msg, ok := messages[key]
+		if !ok {
+			continue
+		}
+		if localizations == nil {
+			localizations = make(map[discord.Locale]string)
+		}
+		localizations[locale] = msg
+	}
+	return localizations
+}
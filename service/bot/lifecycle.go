@@ -0,0 +1,83 @@
+// Package bot provides the core functionality for the Discord bot
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// tasks tracks in-flight interaction handlers and background jobs so shutdown can wait for them
+type tasks struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	closed bool
+}
+
+// add registers a new task, returning false once shutdown has started
+func (t *tasks) add() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return false
+	}
+	t.wg.Add(1)
+	return true
+}
+
+// close stops accepting tasks and waits for running ones until the timeout elapses.
+// It reports whether all tasks finished in time.
+func (t *tasks) close(timeout time.Duration) bool {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Context returns the root context of the bot, which is cancelled when shutdown completes draining
+func (b *Bot) Context() context.Context {
+	return b.ctx
+}
+
+// Go runs fn in a goroutine tracked by the shutdown drain.
+// It returns false without running fn if the bot is shutting down.
+func (b *Bot) Go(fn func()) bool {
+	if !b.tasks.add() {
+		return false
+	}
+
+	go func() {
+		defer b.tasks.wg.Done()
+		fn()
+	}()
+	return true
+}
+
+// drain waits for in-flight handlers and background jobs up to the configured shutdown timeout
+func (b *Bot) drain() {
+	timeout := b.Config.Bot.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	slog.Info("Waiting for in-flight work to finish", slog.Duration("timeout", timeout))
+	if !b.tasks.close(timeout) {
+		slog.Warn("Shutdown timeout exceeded, cancelling remaining work")
+	}
+}
@@ -0,0 +1,94 @@
+// Package bot provides the core functionality for the Discord bot
+package bot
+
+import (
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/topi314/tint"
+	"github.com/zokiio/mukabi/internal/log"
+)
+
+// reloader tracks the configuration last applied by Reload
+type reloader struct {
+	mu      sync.Mutex
+	applied *Config
+}
+
+// reloadableClient is implemented by external clients whose settings can change at runtime
+type reloadableClient interface {
+	SetAPIKey(apiKey string)
+	SetCacheTTL(ttl time.Duration)
+}
+
+// restartSetting describes a setting that only takes effect after a restart
+type restartSetting struct {
+	name  string
+	value func(cfg Config) any
+}
+
+// restartSettings lists all settings that cannot be changed while the bot is running
+var restartSettings = []restartSetting{
+	{"bot.dev_mode", func(cfg Config) any { return cfg.Bot.DevMode }},
+	{"bot.sync_commands", func(cfg Config) any { return cfg.Bot.SyncCommands }},
+	{"bot.guild_ids", func(cfg Config) any { return cfg.Bot.GuildIDs }},
+	{"bot.gateway_url", func(cfg Config) any { return cfg.Bot.GatewayURL }},
+	{"bot.rest_url", func(cfg Config) any { return cfg.Bot.RestURL }},
+	{"bot.token", func(cfg Config) any { return cfg.Bot.Token }},
+	{"bot.shutdown_timeout", func(cfg Config) any { return cfg.Bot.ShutdownTimeout }},
+	{"database", func(cfg Config) any { return cfg.Database }},
+	{"retention", func(cfg Config) any { return cfg.Retention }},
+	{"http", func(cfg Config) any { return cfg.HTTP }},
+}
+
+// Reload applies the settings of a freshly loaded and validated configuration that are safe
+// to change at runtime: logging and external service settings. Changes to any other setting
+// are logged and only take effect after a restart.
+func (b *Bot) Reload(cfg Config) {
+	b.reloader.mu.Lock()
+	defer b.reloader.mu.Unlock()
+
+	current := b.Config
+	if b.reloader.applied != nil {
+		current = *b.reloader.applied
+	}
+
+	var applied []string
+	// Secrets are redacted from forwarded logs, so their changes require a new setup as well
+	if !reflect.DeepEqual(cfg.Log, current.Log) || !reflect.DeepEqual(cfg.LogOptions(), current.LogOptions()) {
+		if err := log.Setup(cfg.Log, cfg.LogOptions()...); err != nil {
+			slog.Error("Failed to apply log settings", tint.Err(err))
+		} else {
+			applied = append(applied, "log")
+		}
+	}
+
+	// Fakes and other implementations without runtime settings are left as they are
+	if raiderIO, ok := b.External.RaiderIO().(reloadableClient); ok {
+		if cfg.External.RaiderIOKey != current.External.RaiderIOKey {
+			raiderIO.SetAPIKey(cfg.External.RaiderIOKey)
+			applied = append(applied, "external.raiderio_key")
+		}
+		if cfg.External.RaiderIOCacheTTL != current.External.RaiderIOCacheTTL {
+			raiderIO.SetCacheTTL(cfg.External.raiderIOCacheTTL())
+			applied = append(applied, "external.raiderio_cache_ttl")
+		}
+	}
+
+	// Compare against the running configuration, since these were never applied
+	var restart []string
+	for _, setting := range restartSettings {
+		if !reflect.DeepEqual(setting.value(cfg), setting.value(b.Config)) {
+			restart = append(restart, setting.name)
+		}
+	}
+
+	b.reloader.applied = &cfg
+
+	slog.Info("Configuration reloaded", slog.Any("applied", applied))
+	if len(restart) > 0 {
+		slog.Warn("Some changed settings require a restart to take effect", slog.Any("settings", restart))
+	}
+}
@@ -0,0 +1,56 @@
+// Package bot provides the core functionality for the Discord bot
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/topi314/tint"
+)
+
+const defaultPurgeInterval = 24 * time.Hour
+
+// runRetention periodically purges data of departed guilds and members until the context is cancelled
+func (b *Bot) runRetention(ctx context.Context) {
+	cfg := b.Config.Retention
+	if cfg.PurgeAfterDays <= 0 {
+		slog.Info("Data retention purge disabled")
+		return
+	}
+
+	interval := cfg.PurgeInterval
+	if interval <= 0 {
+		interval = defaultPurgeInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		b.purgeExpired(ctx, cfg.PurgeAfterDays)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeExpired removes data soft-deleted more than the given number of days ago
+func (b *Bot) purgeExpired(ctx context.Context, days int) {
+	cutoff := time.Now().AddDate(0, 0, -days)
+
+	result, err := b.Database.PurgeExpired(ctx, cutoff)
+	if err != nil {
+		slog.Error("Failed to purge expired data", tint.Err(err))
+		return
+	}
+
+	slog.Info("Purged expired data",
+		slog.Time("cutoff", cutoff),
+		slog.Int64("servers", result.Servers),
+		slog.Int64("characters", result.Characters),
+	)
+}
@@ -4,17 +4,41 @@
 -- Servers table stores basic information about Discord servers the bot is in
 CREATE TABLE IF NOT EXISTS servers (
     server_id TEXT PRIMARY KEY,  -- Discord server/guild ID
-    server_name TEXT             -- Discord server/guild name
+    server_name TEXT,            -- Discord server/guild name
+    left_at TIMESTAMPTZ          -- When the bot left the server, NULL while active
 );
 
+-- Add columns introduced after the initial schema
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS left_at TIMESTAMPTZ;
+
 -- WoW characters table stores World of Warcraft character information for Discord users
 CREATE TABLE IF NOT EXISTS wow_characters (
     discord_id TEXT,     -- Discord user ID
     server_id TEXT,      -- Discord server/guild ID
-    character_name TEXT, -- WoW character name
-    region TEXT,         -- WoW region (e.g., 'eu', 'us')
-    realm TEXT,          -- WoW realm name
-    PRIMARY KEY (discord_id, server_id, character_name),
-    FOREIGN KEY (server_id) REFERENCES servers(server_id)
+    character_name TEXT, -- WoW character name as returned by Raider.IO
+    character_key TEXT NOT NULL, -- Lowercase character name identifying the character on its realm
+    region TEXT,         -- WoW region code (us, eu, kr, tw, cn)
+    realm TEXT,          -- WoW realm slug, e.g. twisting-nether
+    deleted_at TIMESTAMPTZ, -- When the member left the server, NULL while active
+    PRIMARY KEY (discord_id, server_id, region, realm, character_key),
+    FOREIGN KEY (server_id) REFERENCES servers(server_id) ON DELETE CASCADE
 );
 
+ALTER TABLE wow_characters ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Filled and made part of the primary key by the character identity migration for tables keyed on the name
+ALTER TABLE wow_characters ADD COLUMN IF NOT EXISTS character_key TEXT;
+
+-- Make characters follow their server on delete for tables created before cascading was added
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'wow_characters_server_id_fkey' AND confdeltype <> 'c'
+    ) THEN
+        ALTER TABLE wow_characters DROP CONSTRAINT wow_characters_server_id_fkey;
+        ALTER TABLE wow_characters ADD CONSTRAINT wow_characters_server_id_fkey
+            FOREIGN KEY (server_id) REFERENCES servers(server_id) ON DELETE CASCADE;
+    END IF;
+END $$;
+
@@ -0,0 +1,22 @@
+-- SQLite database schema for the Discord bot
+-- Mirrors schema.sql, timestamps are stored as UTC text so they can be compared lexically
+
+-- Servers table stores basic information about Discord servers the bot is in
+CREATE TABLE IF NOT EXISTS servers (
+    server_id TEXT PRIMARY KEY,  -- Discord server/guild ID
+    server_name TEXT,            -- Discord server/guild name
+    left_at DATETIME             -- When the bot left the server, NULL while active
+);
+
+-- WoW characters table stores World of Warcraft character information for Discord users
+CREATE TABLE IF NOT EXISTS wow_characters (
+    discord_id TEXT,     -- Discord user ID
+    server_id TEXT,      -- Discord server/guild ID
+    character_name TEXT, -- WoW character name as returned by Raider.IO
+    character_key TEXT NOT NULL, -- Lowercase character name identifying the character on its realm
+    region TEXT,         -- WoW region code (us, eu, kr, tw, cn)
+    realm TEXT,          -- WoW realm slug, e.g. twisting-nether
+    deleted_at DATETIME, -- When the member left the server, NULL while active
+    PRIMARY KEY (discord_id, server_id, region, realm, character_key),
+    FOREIGN KEY (server_id) REFERENCES servers(server_id) ON DELETE CASCADE
+);
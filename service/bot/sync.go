@@ -0,0 +1,317 @@
+// Package bot provides the core functionality for the Discord bot
+package bot
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// CommandAction describes what a command sync does with a single command
+type CommandAction string
+
+// Command sync actions
+const (
+	CommandCreate CommandAction = "create"
+	CommandUpdate CommandAction = "update"
+	CommandDelete CommandAction = "delete"
+)
+
+// CommandChange is a single change required to bring registered commands in line with the desired ones
+type CommandChange struct {
+	Action  CommandAction
+	Name    string
+	Type    discord.ApplicationCommandType
+	GuildID *snowflake.ID                    // Guild the command is registered in, nil for global commands
+	ID      snowflake.ID                     // ID of the registered command for updates and deletes
+	Command discord.ApplicationCommandCreate // Desired definition for creates and updates
+}
+
+// String returns a human readable description of the change
+func (c CommandChange) String() string {
+	scope := "global"
+	if c.GuildID != nil {
+		scope = "guild " + c.GuildID.String()
+	}
+	return fmt.Sprintf("%s %s command %q (%s)", c.Action, commandTypeName(c.Type), c.Name, scope)
+}
+
+// commandTypeName returns a readable name for an application command type
+func commandTypeName(t discord.ApplicationCommandType) string {
+	switch t {
+	case discord.ApplicationCommandTypeSlash:
+		return "slash"
+	case discord.ApplicationCommandTypeUser:
+		return "user"
+	case discord.ApplicationCommandTypeMessage:
+		return "message"
+	default:
+		return fmt.Sprintf("type-%d", t)
+	}
+}
+
+// CommandSyncer syncs application commands through the Discord REST API
+type CommandSyncer struct {
+	rest     rest.Rest
+	appID    snowflake.ID
+	guildIDs []snowflake.ID
+}
+
+// NewCommandSyncer creates a syncer with a REST-only client for the configured token and REST URL.
+// Unlike New it neither connects to the gateway nor opens the database.
+func NewCommandSyncer(cfg Config) (*CommandSyncer, error) {
+	appID, err := applicationIDFromToken(cfg.Bot.Token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse application id from token: %w", err)
+	}
+
+	return &CommandSyncer{
+		rest:     rest.New(rest.NewClient(cfg.Bot.Token, restConfigOpts(cfg)...)),
+		appID:    appID,
+		guildIDs: cfg.Bot.GuildIDs,
+	}, nil
+}
+
+// applicationIDFromToken decodes the application ID from the first part of a bot token
+func applicationIDFromToken(token string) (snowflake.ID, error) {
+	id, _, _ := strings.Cut(token, ".")
+	raw, err := base64.RawStdEncoding.DecodeString(id)
+	if err != nil {
+		return 0, err
+	}
+	return snowflake.Parse(string(raw))
+}
+
+// Close waits for pending requests and closes the REST client
+func (s *CommandSyncer) Close() {
+	s.rest.Close(context.Background())
+}
+
+// commandSyncer returns a syncer using the REST client of the bot
+func (b *Bot) commandSyncer() *CommandSyncer {
+	return &CommandSyncer{
+		rest:     b.Discord.Rest(),
+		appID:    b.Discord.ApplicationID(),
+		guildIDs: b.Config.Bot.GuildIDs,
+	}
+}
+
+// Plan compares the desired commands with the ones registered on Discord
+// and returns the changes needed, either globally or for every configured guild.
+func (s *CommandSyncer) Plan(commands []discord.ApplicationCommandCreate) ([]CommandChange, error) {
+	if len(s.guildIDs) == 0 {
+		existing, err := s.rest.GetGlobalCommands(s.appID, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch global commands: %w", err)
+		}
+		return diffCommands(nil, commands, existing)
+	}
+
+	var changes []CommandChange
+	for _, guildID := range s.guildIDs {
+		existing, err := s.rest.GetGuildCommands(s.appID, guildID, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch commands of guild %s: %w", guildID, err)
+		}
+		guildChanges, err := diffCommands(&guildID, commands, existing)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, guildChanges...)
+	}
+	return changes, nil
+}
+
+// Apply executes the given changes against the Discord API.
+// Updates are sent as creates, which Discord treats as an overwrite of the command with the same name.
+func (s *CommandSyncer) Apply(changes []CommandChange) error {
+	appID, rest := s.appID, s.rest
+
+	for _, change := range changes {
+		slog.Info("Syncing command", slog.String("change", change.String()))
+
+		var err error
+		switch {
+		case change.Action == CommandDelete && change.GuildID == nil:
+			err = rest.DeleteGlobalCommand(appID, change.ID)
+		case change.Action == CommandDelete:
+			err = rest.DeleteGuildCommand(appID, *change.GuildID, change.ID)
+		case change.GuildID == nil:
+			_, err = rest.CreateGlobalCommand(appID, change.Command)
+		default:
+			_, err = rest.CreateGuildCommand(appID, *change.GuildID, change.Command)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s: %w", change, err)
+		}
+	}
+	return nil
+}
+
+// Sync plans and applies the changes needed to register the given commands
+func (s *CommandSyncer) Sync(commands []discord.ApplicationCommandCreate) error {
+	changes, err := s.Plan(commands)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		slog.Info("Commands are up to date")
+		return nil
+	}
+	return s.Apply(changes)
+}
+
+// SyncCommands plans and applies the changes needed to register the given commands
+func (b *Bot) SyncCommands(commands []discord.ApplicationCommandCreate) error {
+	return b.commandSyncer().Sync(commands)
+}
+
+// commandKey identifies a command by type and name, as names are only unique per type
+func commandKey(t discord.ApplicationCommandType, name string) string {
+	return fmt.Sprintf("%d:%s", t, name)
+}
+
+// diffCommands computes the changes between desired and registered commands in one scope
+func diffCommands(guildID *snowflake.ID, desired []discord.ApplicationCommandCreate, existing []discord.ApplicationCommand) ([]CommandChange, error) {
+	registered := make(map[string]discord.ApplicationCommand, len(existing))
+	for _, cmd := range existing {
+		registered[commandKey(cmd.Type(), cmd.Name())] = cmd
+	}
+
+	var changes []CommandChange
+	for _, want := range desired {
+		key := commandKey(want.Type(), want.CommandName())
+		have, ok := registered[key]
+		if !ok {
+			changes = append(changes, CommandChange{
+				Action:  CommandCreate,
+				Name:    want.CommandName(),
+				Type:    want.Type(),
+				GuildID: guildID,
+				Command: want,
+			})
+			continue
+		}
+		delete(registered, key)
+
+		equal, err := commandsEqual(want, have)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare command %q: %w", want.CommandName(), err)
+		}
+		if !equal {
+			changes = append(changes, CommandChange{
+				Action:  CommandUpdate,
+				Name:    want.CommandName(),
+				Type:    want.Type(),
+				GuildID: guildID,
+				ID:      have.ID(),
+				Command: want,
+			})
+		}
+	}
+
+	// Whatever is left is registered on Discord but no longer defined
+	for _, have := range existing {
+		if _, ok := registered[commandKey(have.Type(), have.Name())]; !ok {
+			continue
+		}
+		changes = append(changes, CommandChange{
+			Action:  CommandDelete,
+			Name:    have.Name(),
+			Type:    have.Type(),
+			GuildID: guildID,
+			ID:      have.ID(),
+		})
+	}
+
+	return changes, nil
+}
+
+// comparedKeys are the command fields that can be set when creating a command
+var comparedKeys = []string{
+	"type",
+	"name",
+	"name_localizations",
+	"description",
+	"description_localizations",
+	"options",
+	"default_member_permissions",
+	"nsfw",
+	"contexts",
+	"integration_types",
+}
+
+// defaultedKeys are filled in by Discord when omitted, so they are only compared when set explicitly
+var defaultedKeys = map[string]bool{
+	"contexts":                   true,
+	"integration_types":          true,
+	"default_member_permissions": true,
+}
+
+// commandsEqual reports whether a registered command matches the desired definition
+func commandsEqual(want discord.ApplicationCommandCreate, have discord.ApplicationCommand) (bool, error) {
+	wantFields, err := commandFields(want)
+	if err != nil {
+		return false, err
+	}
+	haveFields, err := commandFields(have)
+	if err != nil {
+		return false, err
+	}
+
+	for _, key := range comparedKeys {
+		w, wantSet := wantFields[key]
+		h := haveFields[key]
+		if !wantSet && defaultedKeys[key] {
+			continue
+		}
+		if isEmptyField(w) && isEmptyField(h) {
+			continue
+		}
+		if !reflect.DeepEqual(w, h) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// commandFields converts a command into its generic JSON representation
+func commandFields(v json.Marshaler) (map[string]any, error) {
+	data, err := v.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// isEmptyField reports whether a JSON value is absent or its type's zero value
+func isEmptyField(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !val
+	case string:
+		return strings.TrimSpace(val) == ""
+	case []any:
+		return len(val) == 0
+	case map[string]any:
+		return len(val) == 0
+	default:
+		return false
+	}
+}
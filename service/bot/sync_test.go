@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// registered parses commands as returned by Discord, including the fields it fills in by default
+func registered(t *testing.T, raw string) []discord.ApplicationCommand {
+	t.Helper()
+	var commands []discord.UnmarshalApplicationCommand
+	if err := json.Unmarshal([]byte(raw), &commands); err != nil {
+		t.Fatalf("failed to parse registered commands: %s", err)
+	}
+	parsed := make([]discord.ApplicationCommand, len(commands))
+	for i, command := range commands {
+		parsed[i] = command.ApplicationCommand
+	}
+	return parsed
+}
+
+// registeredPing is a /ping command and a user command as Discord returns them after creation
+const registeredPing = `[
+	{
+		"id": "100", "application_id": "1", "version": "1", "type": 1,
+		"name": "ping", "name_localizations": null,
+		"description": "Ping the bot", "description_localizations": null,
+		"default_member_permissions": null, "dm_permission": true, "nsfw": false,
+		"contexts": [0, 1, 2], "integration_types": [0]
+	},
+	{
+		"id": "200", "application_id": "1", "version": "1", "type": 2,
+		"name": "Inspect", "description": "",
+		"default_member_permissions": null, "nsfw": false,
+		"contexts": [0, 1, 2], "integration_types": [0]
+	}
+]`
+
+func TestDiffCommands(t *testing.T) {
+	ping := discord.SlashCommandCreate{Name: "ping", Description: "Ping the bot"}
+	inspect := discord.UserCommandCreate{Name: "Inspect"}
+
+	tests := []struct {
+		name    string
+		desired []discord.ApplicationCommandCreate
+		want    []CommandChange
+	}{
+		{
+			name:    "unchanged",
+			desired: []discord.ApplicationCommandCreate{ping, inspect},
+		},
+		{
+			name: "empty fields",
+			desired: []discord.ApplicationCommandCreate{
+				discord.SlashCommandCreate{
+					Name:                     "ping",
+					Description:              "Ping the bot",
+					NameLocalizations:        map[discord.Locale]string{},
+					DescriptionLocalizations: map[discord.Locale]string{},
+					Options:                  []discord.ApplicationCommandOption{},
+				},
+				inspect,
+			},
+		},
+		{
+			name: "defaulted fields set to the defaults",
+			desired: []discord.ApplicationCommandCreate{
+				discord.SlashCommandCreate{
+					Name:             "ping",
+					Description:      "Ping the bot",
+					Contexts:         []discord.InteractionContextType{discord.InteractionContextTypeGuild, discord.InteractionContextTypeBotDM, discord.InteractionContextTypePrivateChannel},
+					IntegrationTypes: []discord.ApplicationIntegrationType{discord.ApplicationIntegrationTypeGuildInstall},
+				},
+				inspect,
+			},
+		},
+		{
+			name: "changed description",
+			desired: []discord.ApplicationCommandCreate{
+				discord.SlashCommandCreate{Name: "ping", Description: "Check the latency"},
+				inspect,
+			},
+			want: []CommandChange{{Action: CommandUpdate, Name: "ping", Type: discord.ApplicationCommandTypeSlash, ID: 100}},
+		},
+		{
+			name: "changed defaulted field",
+			desired: []discord.ApplicationCommandCreate{
+				ping,
+				discord.UserCommandCreate{Name: "Inspect", Contexts: []discord.InteractionContextType{discord.InteractionContextTypeGuild}},
+			},
+			want: []CommandChange{{Action: CommandUpdate, Name: "Inspect", Type: discord.ApplicationCommandTypeUser, ID: 200}},
+		},
+		{
+			name: "added option",
+			desired: []discord.ApplicationCommandCreate{
+				discord.SlashCommandCreate{
+					Name:        "ping",
+					Description: "Ping the bot",
+					Options: []discord.ApplicationCommandOption{
+						discord.ApplicationCommandOptionBool{Name: "verbose", Description: "Show details"},
+					},
+				},
+				inspect,
+			},
+			want: []CommandChange{{Action: CommandUpdate, Name: "ping", Type: discord.ApplicationCommandTypeSlash, ID: 100}},
+		},
+		{
+			name: "added command",
+			desired: []discord.ApplicationCommandCreate{
+				ping,
+				inspect,
+				discord.SlashCommandCreate{Name: "help", Description: "Show help"},
+			},
+			want: []CommandChange{{Action: CommandCreate, Name: "help", Type: discord.ApplicationCommandTypeSlash}},
+		},
+		{
+			name:    "removed command",
+			desired: []discord.ApplicationCommandCreate{ping},
+			want:    []CommandChange{{Action: CommandDelete, Name: "Inspect", Type: discord.ApplicationCommandTypeUser, ID: 200}},
+		},
+		{
+			name: "same name with another type",
+			desired: []discord.ApplicationCommandCreate{
+				ping,
+				inspect,
+				discord.UserCommandCreate{Name: "ping"},
+			},
+			want: []CommandChange{{Action: CommandCreate, Name: "ping", Type: discord.ApplicationCommandTypeUser}},
+		},
+		{
+			name: "everything removed",
+			want: []CommandChange{
+				{Action: CommandDelete, Name: "ping", Type: discord.ApplicationCommandTypeSlash, ID: 100},
+				{Action: CommandDelete, Name: "Inspect", Type: discord.ApplicationCommandTypeUser, ID: 200},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guildID := snowflake.ID(42)
+			changes, err := diffCommands(&guildID, tt.desired, registered(t, registeredPing))
+			if err != nil {
+				t.Fatalf("diffCommands() error = %s", err)
+			}
+			if len(changes) != len(tt.want) {
+				t.Fatalf("diffCommands() = %v, want %d changes", changes, len(tt.want))
+			}
+			for i, change := range changes {
+				want := tt.want[i]
+				if change.Action != want.Action || change.Name != want.Name || change.Type != want.Type || change.ID != want.ID {
+					t.Errorf("change %d = %s (id %s), want %s (id %s)", i, change, change.ID, want, want.ID)
+				}
+				if change.GuildID == nil || *change.GuildID != guildID {
+					t.Errorf("change %d guild = %v, want %s", i, change.GuildID, guildID)
+				}
+				if (change.Command == nil) != (want.Action == CommandDelete) {
+					t.Errorf("change %d command = %v, want a definition unless deleting", i, change.Command)
+				}
+			}
+		})
+	}
+}